@@ -71,12 +71,12 @@ func (b *Adapter) GetObject(ctx context.Context, id domain.KindName, baseObject
 	return client.GetObject(ctx, id, baseObject)
 }
 
-func (b *Adapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+func (b *Adapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
 	client, err := b.getClient(ctx)
 	if err != nil {
 		return err
 	}
-	return client.PatchObject(ctx, id, checksum, patch)
+	return client.PatchObject(ctx, id, checksum, patchType, patch)
 }
 
 func (b *Adapter) PutObject(ctx context.Context, id domain.KindName, object []byte) error {
@@ -186,6 +186,33 @@ func (b *Adapter) Batch(ctx context.Context, kind domain.Kind, batchType domain.
 	return client.Batch(ctx, kind, batchType, items)
 }
 
+func (b *Adapter) WriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	client, err := b.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.WriteFailed(ctx, id, reason, message)
+}
+
+func (b *Adapter) ResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	client, err := b.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ResourceStatus(ctx, kind, state, message)
+}
+
+func (b *Adapter) PermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	client, err := b.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.PermissionReport(ctx, kind, missing, message)
+}
+
 // startReconciliationPeriodicTask starts a periodic task that sends reconciliation request messages to connected clients
 // every configurable minutes (interval). If interval is 0 (not set), the task is disabled.
 // intervalFromConnection is the minimum interval time in minutes from the connection time that the reconciliation task will be sent.
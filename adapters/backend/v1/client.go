@@ -96,9 +96,9 @@ func (c *Client) GetObject(ctx context.Context, id domain.KindName, baseObject [
 	return c.sendGetObjectMessage(ctx, id, baseObject)
 }
 
-func (c *Client) PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+func (c *Client) PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
 	// patching the object is delegated to the ingester, it might emit a GetObject message if the patching fails
-	return c.sendPatchObjectMessage(ctx, id, checksum, patch)
+	return c.sendPatchObjectMessage(ctx, id, checksum, patchType, patch)
 }
 
 func (c *Client) PutObject(ctx context.Context, id domain.KindName, object []byte) error {
@@ -107,6 +107,10 @@ func (c *Client) PutObject(ctx context.Context, id domain.KindName, object []byt
 }
 
 func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.BatchType, items domain.BatchItems) error {
+	if batchType == domain.ReconciliationBatch {
+		// inventory sent by the client, reconciling is delegated to the ingester
+		return c.sendClientReconciliationRequestMessage(ctx, kind, items.NewChecksum)
+	}
 	var err error
 	for _, item := range items.GetObject {
 		id := domain.KindName{
@@ -114,6 +118,7 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, c.GetObject(ctx, id, []byte(item.BaseObject)))
 	}
@@ -124,6 +129,7 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, c.VerifyObject(ctx, id, item.Checksum))
 	}
@@ -134,6 +140,7 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, c.DeleteObject(ctx, id))
 	}
@@ -144,8 +151,9 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
-		err = multierr.Append(err, c.PatchObject(ctx, id, item.Checksum, []byte(item.Patch)))
+		err = multierr.Append(err, c.PatchObject(ctx, id, item.Checksum, item.PatchType, []byte(item.Patch)))
 	}
 
 	for _, item := range items.PutObject {
@@ -154,6 +162,7 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, c.PutObject(ctx, id, []byte(item.Object)))
 	}
@@ -173,19 +182,36 @@ func (c *Client) VerifyObject(ctx context.Context, id domain.KindName, checksum
 	return c.sendVerifyObjectMessage(ctx, id, checksum)
 }
 
+func (c *Client) WriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	// failed writes into the cluster are reported to the backend
+	return c.sendWriteFailedMessage(ctx, id, reason, message)
+}
+
+func (c *Client) ResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	// the synchronization state of cluster resources is reported to the backend
+	return c.sendResourceStatusMessage(ctx, kind, state, message)
+}
+
+func (c *Client) PermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	// the RBAC permissions missing on cluster resources are reported to the backend
+	return c.sendPermissionReportMessage(ctx, kind, missing, message)
+}
+
 func (c *Client) sendDeleteObjectMessage(ctx context.Context, id domain.KindName) error {
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	cId := utils.ClientIdentifierFromContext(ctx)
 
 	msg := messaging.DeleteObjectMessage{
-		Cluster:   cId.Cluster,
-		Account:   cId.Account,
-		Depth:     depth + 1,
-		Kind:      id.Kind.String(),
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	logger.L().Debug("sending delete object message to producer",
 		helpers.String("account", msg.Account),
@@ -208,14 +234,16 @@ func (c *Client) sendGetObjectMessage(ctx context.Context, id domain.KindName, b
 	cId := utils.ClientIdentifierFromContext(ctx)
 
 	msg := messaging.GetObjectMessage{
-		BaseObject: baseObject,
-		Cluster:    cId.Cluster,
-		Account:    cId.Account,
-		Depth:      depth + 1,
-		Kind:       id.Kind.String(),
-		MsgId:      msgId,
-		Name:       id.Name,
-		Namespace:  id.Namespace,
+		BaseObject:      baseObject,
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	logger.L().Debug("sending get object message to producer",
 		helpers.String("account", msg.Account),
@@ -233,21 +261,24 @@ func (c *Client) sendGetObjectMessage(ctx context.Context, id domain.KindName, b
 	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValueGetObjectMessage, data)
 }
 
-func (c *Client) sendPatchObjectMessage(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+func (c *Client) sendPatchObjectMessage(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	cId := utils.ClientIdentifierFromContext(ctx)
 
 	msg := messaging.PatchObjectMessage{
-		Checksum:  checksum,
-		Cluster:   cId.Cluster,
-		Account:   cId.Account,
-		Depth:     depth + 1,
-		Kind:      id.Kind.String(),
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
-		Patch:     patch,
+		Checksum:        checksum,
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Patch:           patch,
+		PatchType:       string(patchType),
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	logger.L().Debug("sending patch object message to producer",
 		helpers.String("account", msg.Account),
@@ -256,6 +287,7 @@ func (c *Client) sendPatchObjectMessage(ctx context.Context, id domain.KindName,
 		helpers.String("msgid", msg.MsgId),
 		helpers.String("name", id.Name),
 		helpers.String("checksum", msg.Checksum),
+		helpers.String("patch type", msg.PatchType),
 		helpers.Int("patch size", len(msg.Patch)))
 
 	data, err := json.Marshal(msg)
@@ -272,14 +304,16 @@ func (c *Client) sendPutObjectMessage(ctx context.Context, id domain.KindName, o
 	cId := utils.ClientIdentifierFromContext(ctx)
 
 	msg := messaging.PutObjectMessage{
-		Cluster:   cId.Cluster,
-		Account:   cId.Account,
-		Depth:     depth + 1,
-		Kind:      id.Kind.String(),
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
-		Object:    object,
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Object:          object,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	logger.L().Debug("sending put object message to producer",
 		helpers.String("account", msg.Account),
@@ -303,14 +337,16 @@ func (c *Client) sendVerifyObjectMessage(ctx context.Context, id domain.KindName
 	cId := utils.ClientIdentifierFromContext(ctx)
 
 	msg := messaging.VerifyObjectMessage{
-		Checksum:  checksum,
-		Cluster:   cId.Cluster,
-		Account:   cId.Account,
-		Depth:     depth + 1,
-		Kind:      id.Kind.String(),
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
+		Checksum:        checksum,
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	logger.L().Debug("sending verify object message to producer",
 		helpers.String("account", msg.Account),
@@ -328,6 +364,136 @@ func (c *Client) sendVerifyObjectMessage(ctx context.Context, id domain.KindName
 	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValueVerifyObjectMessage, data)
 }
 
+func (c *Client) sendWriteFailedMessage(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	cId := utils.ClientIdentifierFromContext(ctx)
+
+	msg := messaging.WriteFailedMessage{
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		Kind:            id.Kind.String(),
+		Message:         message,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Reason:          string(reason),
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
+	}
+	logger.L().Debug("sending write failed message to producer",
+		helpers.String("account", msg.Account),
+		helpers.String("cluster", msg.Cluster),
+		helpers.String("kind", id.Kind.String()),
+		helpers.String("msgid", msg.MsgId),
+		helpers.String("name", id.Name),
+		helpers.String("reason", msg.Reason))
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal write failed message: %w", err)
+	}
+
+	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValueWriteFailedMessage, data)
+}
+
+func (c *Client) sendClientReconciliationRequestMessage(ctx context.Context, kind domain.Kind, checksums []domain.NewChecksum) error {
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	cId := utils.ClientIdentifierFromContext(ctx)
+
+	objects := make([]messaging.ReconciliationRequestObject, 0, len(checksums))
+	for _, item := range checksums {
+		objects = append(objects, messaging.ReconciliationRequestObject{
+			Checksum:        item.Checksum,
+			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
+			Name:            item.Name,
+			Namespace:       item.Namespace,
+		})
+	}
+	msg := messaging.ReconciliationRequestMessage{
+		Cluster:         cId.Cluster,
+		Account:         cId.Account,
+		Depth:           depth + 1,
+		MsgId:           msgId,
+		ServerInitiated: false,
+		KindToObjects:   map[string][]messaging.ReconciliationRequestObject{kind.String(): objects},
+	}
+	logger.L().Debug("sending client reconciliation request message to producer",
+		helpers.String("account", msg.Account),
+		helpers.String("cluster", msg.Cluster),
+		helpers.String("kind", kind.String()),
+		helpers.String("msgid", msg.MsgId),
+		helpers.Int("objects", len(objects)))
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal reconciliation request message: %w", err)
+	}
+
+	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValueReconciliationRequestMessage, data)
+}
+
+func (c *Client) sendResourceStatusMessage(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	cId := utils.ClientIdentifierFromContext(ctx)
+
+	msg := messaging.ResourceStatusMessage{
+		Cluster: cId.Cluster,
+		Account: cId.Account,
+		Depth:   depth + 1,
+		Kind:    kind.String(),
+		Message: message,
+		MsgId:   msgId,
+		State:   string(state),
+	}
+	logger.L().Debug("sending resource status message to producer",
+		helpers.String("account", msg.Account),
+		helpers.String("cluster", msg.Cluster),
+		helpers.String("kind", msg.Kind),
+		helpers.String("msgid", msg.MsgId),
+		helpers.String("state", msg.State))
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal resource status message: %w", err)
+	}
+
+	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValueResourceStatusMessage, data)
+}
+
+func (c *Client) sendPermissionReportMessage(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	cId := utils.ClientIdentifierFromContext(ctx)
+
+	msg := messaging.PermissionReportMessage{
+		Cluster: cId.Cluster,
+		Account: cId.Account,
+		Depth:   depth + 1,
+		Kind:    kind.String(),
+		Message: message,
+		Missing: missing,
+		MsgId:   msgId,
+	}
+	logger.L().Debug("sending permission report message to producer",
+		helpers.String("account", msg.Account),
+		helpers.String("cluster", msg.Cluster),
+		helpers.String("kind", msg.Kind),
+		helpers.String("msgid", msg.MsgId),
+		helpers.Int("missing", len(msg.Missing)))
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal permission report message: %w", err)
+	}
+
+	return c.messageProducer.ProduceMessage(ctx, cId, messaging.MsgPropEventValuePermissionReportMessage, data)
+}
+
 func (c *Client) SendReconciliationRequestMessage(ctx context.Context) error {
 	ctx = utils.ContextFromGeneric(ctx, domain.Generic{})
 
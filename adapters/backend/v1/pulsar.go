@@ -181,6 +181,7 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 					Name:            object.Name,
 					Namespace:       object.Namespace,
 					ResourceVersion: object.ResourceVersion,
+					UID:             object.UID,
 					Checksum:        object.Checksum,
 					Kind:            kind,
 					Event:           &event,
@@ -214,6 +215,7 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 			Name:            data.Name,
 			Namespace:       data.Namespace,
 			ResourceVersion: data.ResourceVersion,
+			UID:             data.UID,
 		}, data.BaseObject); err != nil {
 			return fmt.Errorf("failed to send GetObject message: %w", err)
 		}
@@ -239,7 +241,8 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 			Name:            data.Name,
 			Namespace:       data.Namespace,
 			ResourceVersion: data.ResourceVersion,
-		}, data.Checksum, data.Patch); err != nil {
+			UID:             data.UID,
+		}, data.Checksum, domain.PatchType(data.PatchType), data.Patch); err != nil {
 			return fmt.Errorf("failed to send PatchObject message: %w", err)
 		}
 	case messaging.MsgPropEventValueVerifyObjectMessage:
@@ -264,6 +267,7 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 			Name:            data.Name,
 			Namespace:       data.Namespace,
 			ResourceVersion: data.ResourceVersion,
+			UID:             data.UID,
 		}, data.Checksum); err != nil {
 			return fmt.Errorf("failed to send VerifyObject message: %w", err)
 		}
@@ -289,6 +293,7 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 			Name:            data.Name,
 			Namespace:       data.Namespace,
 			ResourceVersion: data.ResourceVersion,
+			UID:             data.UID,
 		}, data.Object); err != nil {
 			return fmt.Errorf("failed to send PutObject message: %w", err)
 		}
@@ -314,6 +319,7 @@ func (c *PulsarMessageReader) handleSingleSynchronizerMessage(ctx context.Contex
 			Name:            data.Name,
 			Namespace:       data.Namespace,
 			ResourceVersion: data.ResourceVersion,
+			UID:             data.UID,
 		}); err != nil {
 			return fmt.Errorf("failed to send DeleteObject message: %w", err)
 		}
@@ -3,6 +3,7 @@ package incluster
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -12,24 +13,54 @@ import (
 	"github.com/kubescape/synchronizer/config"
 	"github.com/kubescape/synchronizer/domain"
 	"github.com/kubescape/synchronizer/utils"
+	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/dynamic"
 )
 
 type Adapter struct {
-	callbacks domain.Callbacks
-	cfg       config.InCluster
-	clients   map[string]adapters.Client
-	k8sclient dynamic.Interface
+	mu            sync.RWMutex
+	callbacks     domain.Callbacks
+	started       bool
+	cfg           config.InCluster
+	clientsMu     sync.RWMutex // clients are also added when the backend sends unknown kinds
+	clients       map[string]adapters.Client
+	watched       []*Client
+	unauthorized  map[string]string
+	permissions   []PermissionReport
+	k8sclient     dynamic.Interface
+	shadowObjects ShadowStore
+	owners        *ownerResolver // shared by the clients, so that resolved owners are cached once
+	retryLimiter  *rate.Limiter  // shared by the clients, so that an outage does not result in a burst of retries
 }
 
 func NewInClusterAdapter(cfg config.InCluster, k8sclient dynamic.Interface) *Adapter {
 	return &Adapter{
-		cfg:       cfg,
-		clients:   map[string]adapters.Client{},
-		k8sclient: k8sclient,
+		cfg:           cfg,
+		clients:       map[string]adapters.Client{},
+		unauthorized:  map[string]string{},
+		k8sclient:     k8sclient,
+		shadowObjects: newShadowStore(cfg.ShadowStore),
+		owners:        newOwnerResolver(k8sclient, nil),
+		retryLimiter:  newRetryLimiter(),
 	}
 }
 
+// newShadowStore returns an on-disk store when configured, falling back to memory if it cannot be opened.
+func newShadowStore(cfg *config.ShadowStore) ShadowStore {
+	if cfg == nil || cfg.Path == "" {
+		return NewMemoryShadowStore()
+	}
+	store, err := NewDiskShadowStore(cfg.Path, int64(cfg.GetMaxSizeMB())*1024*1024)
+	if err != nil {
+		logger.L().Warning("cannot open on-disk shadow store, keeping shadow objects in memory", helpers.Error(err), helpers.String("path", cfg.Path))
+		return NewMemoryShadowStore()
+	}
+	logger.L().Info("using on-disk shadow store", helpers.String("path", cfg.Path), helpers.Int("maxSizeMB", cfg.GetMaxSizeMB()))
+	return store
+}
+
 var _ adapters.Adapter = (*Adapter)(nil)
 
 func (a *Adapter) GetClient(id domain.KindName) (adapters.Client, error) {
@@ -40,20 +71,34 @@ func (a *Adapter) GetClient(id domain.KindName) (adapters.Client, error) {
 	return a.GetClientByKind(*id.Kind), nil
 }
 
+// GetClientByKind returns the client of a configured resource, other kinds get a read-only client
+// so that writes from the backend are refused.
 func (a *Adapter) GetClientByKind(kind domain.Kind) adapters.Client {
+	a.clientsMu.Lock()
+	defer a.clientsMu.Unlock()
 	client, ok := a.clients[kind.String()]
 	if !ok {
-		client = NewClient(a.k8sclient, a.cfg.Account, a.cfg.ClusterName, config.Resource{
-			Group:    kind.Group,
-			Version:  kind.Version,
-			Resource: kind.Resource,
-			Strategy: "copy",
-		})
+		client = NewClient(a.k8sclient, a.cfg, config.Resource{
+			Group:     kind.Group,
+			Version:   kind.Version,
+			Resource:  kind.Resource,
+			Strategy:  domain.CopyStrategy,
+			Direction: domain.ClusterToBackend,
+		}, a.shadowObjects)
+		client.RegisterCallbacks(context.Background(), a.clientCallbacks())
 		a.clients[kind.String()] = client
 	}
 	return client
 }
 
+// configuredClient returns the client of a configured resource, if it was started.
+func (a *Adapter) configuredClient(r config.Resource) (*Client, bool) {
+	a.clientsMu.RLock()
+	defer a.clientsMu.RUnlock()
+	client, ok := a.clients[r.String()].(*Client)
+	return client, ok
+}
+
 func (a *Adapter) DeleteObject(ctx context.Context, id domain.KindName) error {
 	client, err := a.GetClient(id)
 	if err != nil {
@@ -70,12 +115,12 @@ func (a *Adapter) GetObject(ctx context.Context, id domain.KindName, baseObject
 	return client.GetObject(ctx, id, baseObject)
 }
 
-func (a *Adapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+func (a *Adapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
 	client, err := a.GetClient(id)
 	if err != nil {
 		return fmt.Errorf("failed to get client for resource %s: %w", id.Kind, err)
 	}
-	return client.PatchObject(ctx, id, checksum, patch)
+	return client.PatchObject(ctx, id, checksum, patchType, patch)
 }
 
 func (a *Adapter) PutObject(ctx context.Context, id domain.KindName, object []byte) error {
@@ -95,41 +140,292 @@ func (a *Adapter) VerifyObject(ctx context.Context, id domain.KindName, checksum
 }
 
 func (a *Adapter) Batch(ctx context.Context, kind domain.Kind, batchType domain.BatchType, items domain.BatchItems) error {
+	if batchType == domain.DefaultBatch {
+		// items can be of different kinds depending on each other
+		return a.orderedBatch(ctx, kind, items)
+	}
 	return a.GetClientByKind(kind).Batch(ctx, kind, batchType, items)
 }
 
+func (a *Adapter) WriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	client, err := a.GetClient(id)
+	if err != nil {
+		return fmt.Errorf("failed to get client for resource %s: %w", id.Kind, err)
+	}
+	return client.WriteFailed(ctx, id, reason, message)
+}
+
+func (a *Adapter) ResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	return a.GetClientByKind(kind).ResourceStatus(ctx, kind, state, message)
+}
+
+func (a *Adapter) PermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	return a.GetClientByKind(kind).PermissionReport(ctx, kind, missing, message)
+}
+
+// RegisterCallbacks can be called at any time, clients always use the latest callbacks.
 func (a *Adapter) RegisterCallbacks(_ context.Context, callbacks domain.Callbacks) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.callbacks = callbacks
 }
 
 func (a *Adapter) Callbacks(_ context.Context) (domain.Callbacks, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return a.callbacks, nil
 }
 
+// clientCallbacks returns callbacks forwarding to the ones currently registered.
+// Until callbacks are registered, e.g. while standing by for the leadership, events are dropped
+// but the clients keep watching the cluster and updating their shadow objects.
+func (a *Adapter) clientCallbacks() domain.Callbacks {
+	current := func() domain.Callbacks {
+		callbacks, _ := a.Callbacks(context.Background())
+		return callbacks
+	}
+	return domain.Callbacks{
+		DeleteObject: func(ctx context.Context, id domain.KindName) error {
+			if cb := current().DeleteObject; cb != nil {
+				return cb(ctx, id)
+			}
+			return nil
+		},
+		GetObject: func(ctx context.Context, id domain.KindName, baseObject []byte) error {
+			if cb := current().GetObject; cb != nil {
+				return cb(ctx, id, baseObject)
+			}
+			return nil
+		},
+		PatchObject: func(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
+			if cb := current().PatchObject; cb != nil {
+				return cb(ctx, id, checksum, patchType, patch)
+			}
+			return nil
+		},
+		PutObject: func(ctx context.Context, id domain.KindName, object []byte) error {
+			if cb := current().PutObject; cb != nil {
+				return cb(ctx, id, object)
+			}
+			return nil
+		},
+		VerifyObject: func(ctx context.Context, id domain.KindName, checksum string) error {
+			if cb := current().VerifyObject; cb != nil {
+				return cb(ctx, id, checksum)
+			}
+			return nil
+		},
+		Batch: func(ctx context.Context, kind domain.Kind, batchType domain.BatchType, items domain.BatchItems) error {
+			if cb := current().Batch; cb != nil {
+				return cb(ctx, kind, batchType, items)
+			}
+			return nil
+		},
+		WriteFailed: func(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+			if cb := current().WriteFailed; cb != nil {
+				return cb(ctx, id, reason, message)
+			}
+			return nil
+		},
+		ResourceStatus: func(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+			if cb := current().ResourceStatus; cb != nil {
+				return cb(ctx, kind, state, message)
+			}
+			return nil
+		},
+		PermissionReport: func(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+			if cb := current().PermissionReport; cb != nil {
+				return cb(ctx, kind, missing, message)
+			}
+			return nil
+		},
+	}
+}
+
+// Start starts watching the configured resources. When called again, e.g. when a standby
+// replica takes over the leadership, the clients already running send the checksums of all
+// their objects, so that the backend catches up with the changes made in the meantime.
 func (a *Adapter) Start(ctx context.Context) error {
+	a.mu.Lock()
+	started := a.started
+	a.started = true
+	a.mu.Unlock()
+	if started {
+		a.sendPermissionReports(ctx)
+		a.resync(ctx)
+		return nil
+	}
+	a.sendPermissionReports(ctx)
 	for _, r := range a.cfg.Resources {
-		client := NewClient(a.k8sclient, a.cfg.Account, a.cfg.ClusterName, r)
-		client.RegisterCallbacks(ctx, a.callbacks)
+		client := NewClient(a.k8sclient, a.cfg, r, a.shadowObjects)
+		client.owners = a.owners
+		client.retries = newWriteRetries(r.GetMaxWriteAttempts(), a.retryLimiter)
+		client.RegisterCallbacks(ctx, a.clientCallbacks())
+		a.clientsMu.Lock()
 		a.clients[r.String()] = client
+		a.clientsMu.Unlock()
+		// writes from the backend are retried for all resources, watched or not
+		client.startRetries(ctx)
+
+		if reason, ok := a.unauthorized[r.String()]; ok {
+			// cannot work without permissions, reported as degraded until restarted with the right RBAC
+			a.mu.Lock()
+			a.watched = append(a.watched, client)
+			a.mu.Unlock()
+			client.setState(ctx, domain.ResourceDegraded, reason)
+			continue
+		}
 
+		if !r.Direction.Watched() {
+			// objects are only written from the backend, no need to watch them
+			continue
+		}
+		a.mu.Lock()
+		a.watched = append(a.watched, client)
+		a.mu.Unlock()
 		go func() {
+			// a failing resource is degraded and retried on a slow schedule, the others keep syncing
 			if err := backoff.RetryNotify(func() error {
 				return client.Start(ctx)
-			}, utils.NewBackOff(), func(err error, d time.Duration) {
+			}, client.newBackOff(ctx), func(err error, d time.Duration) {
+				client.markFailure(ctx, err)
 				logger.L().Ctx(ctx).Warning("start client", helpers.Error(err),
 					helpers.String("resource", client.res.Resource),
 					helpers.String("retry in", d.String()))
-			}); err != nil {
-				logger.L().Ctx(ctx).Fatal("giving up start client", helpers.Error(err),
+			}); err != nil && ctx.Err() == nil {
+				client.setState(ctx, domain.ResourceDegraded, err.Error())
+				logger.L().Ctx(ctx).Error("giving up start client", helpers.Error(err),
 					helpers.String("resource", client.res.Resource))
 			}
 		}()
 	}
+	a.startReconciliationPeriodicTask(ctx)
 	return nil
 }
 
+// startReconciliationPeriodicTask periodically sends the inventory of the watched resources to the backend,
+// so that it can request or delete the objects that differ. If the interval is 0 (not set), the task is disabled.
+func (a *Adapter) startReconciliationPeriodicTask(ctx context.Context) {
+	if a.cfg.ReconciliationIntervalSeconds <= 0 {
+		logger.L().Info("client reconciliation task is disabled (interval is not set)")
+		return
+	}
+	go func() {
+		logger.L().Info("starting client reconciliation periodic task", helpers.Int("intervalSeconds", a.cfg.ReconciliationIntervalSeconds))
+		ticker := time.NewTicker(time.Duration(a.cfg.ReconciliationIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, r := range a.cfg.Resources {
+					if !r.Direction.Watched() {
+						continue
+					}
+					client, ok := a.configuredClient(r)
+					if !ok || client.health.degraded() {
+						continue
+					}
+					if err := client.sendReconciliationInventory(ctx); err != nil {
+						logger.L().Ctx(ctx).Error("failed to send reconciliation inventory", helpers.Error(err),
+							helpers.String("resource", client.res.Resource))
+					}
+				}
+			}
+		}
+	}()
+}
+
+// SetRESTMapper sets the mapper used to resolve the top-level owners of children, which are not annotated without it.
+// It must be called before Start.
+func (a *Adapter) SetRESTMapper(mapper meta.RESTMapper) {
+	a.owners.mapper = mapper
+}
+
+// SkipUnauthorized excludes the resources missing permissions from the synchronization,
+// it must be called before Start. The reports are sent to the backend on Start.
+func (a *Adapter) SkipUnauthorized(reports []PermissionReport) {
+	a.permissions = reports
+	for _, report := range reports {
+		if !report.Allowed() {
+			a.unauthorized[report.Resource.String()] = report.String()
+		}
+	}
+}
+
+// sendPermissionReports sends the permission reports of the configured resources to the backend.
+func (a *Adapter) sendPermissionReports(ctx context.Context) {
+	callbacks := a.clientCallbacks()
+	ctx = utils.ContextFromGeneric(ctx, domain.Generic{})
+	for _, report := range a.permissions {
+		kind := domain.Kind{Group: report.Resource.Group, Version: report.Resource.Version, Resource: report.Resource.Resource}
+		var message string
+		if report.Err != nil {
+			message = report.Err.Error()
+		}
+		if err := callbacks.PermissionReport(ctx, kind, report.Missing, message); err != nil {
+			logger.L().Ctx(ctx).Error("cannot send permission report", helpers.Error(err), helpers.String("resource", report.Resource.String()))
+		}
+	}
+}
+
+// Health returns the synchronization state of the watched resources.
+func (a *Adapter) Health() Health {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	health := Health{Resources: map[string]ResourceHealth{}}
+	for _, client := range a.watched {
+		current := client.Health()
+		health.Resources[client.kind.String()] = current
+		health.Degraded = health.Degraded || current.State == domain.ResourceDegraded
+	}
+	return health
+}
+
+// resync sends the checksums of all objects of the watched resources,
+// and the state of the degraded ones as the backend may have missed it.
+func (a *Adapter) resync(ctx context.Context) {
+	for _, r := range a.cfg.Resources {
+		if !r.Direction.Watched() {
+			continue
+		}
+		client, ok := a.configuredClient(r)
+		if !ok {
+			continue
+		}
+		if client.health.degraded() {
+			client.reportState(ctx)
+			continue
+		}
+		go func() {
+			if err := backoff.RetryNotify(func() error {
+				return client.verifyAll(ctx)
+			}, utils.NewBackOff(), func(err error, d time.Duration) {
+				logger.L().Ctx(ctx).Warning("resync client", helpers.Error(err),
+					helpers.String("resource", client.res.Resource),
+					helpers.String("retry in", d.String()))
+			}); err != nil {
+				logger.L().Ctx(ctx).Error("giving up resync client", helpers.Error(err),
+					helpers.String("resource", client.res.Resource))
+			}
+		}()
+	}
+}
+
+// Stop stops retrying the failed writes of the clients.
 func (a *Adapter) Stop(ctx context.Context) error {
-	return nil
+	a.clientsMu.RLock()
+	clients := make([]adapters.Client, 0, len(a.clients))
+	for _, client := range a.clients {
+		clients = append(clients, client)
+	}
+	a.clientsMu.RUnlock()
+	var err error
+	for _, client := range clients {
+		err = multierr.Append(err, client.Stop(ctx))
+	}
+	return err
 }
 
 func (a *Adapter) IsRelated(ctx context.Context, id domain.ClientIdentifier) bool {
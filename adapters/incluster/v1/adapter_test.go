@@ -0,0 +1,40 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/synchronizer/config"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestAdapter_clientCallbacks(t *testing.T) {
+	a := NewInClusterAdapter(config.InCluster{}, fake.NewSimpleDynamicClient(runtime.NewScheme()))
+	callbacks := a.clientCallbacks()
+	id := domain.KindName{Kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, Name: "test"}
+	// standing by, events are dropped
+	assert.NoError(t, callbacks.VerifyObject(context.TODO(), id, "checksum"))
+	assert.NoError(t, callbacks.DeleteObject(context.TODO(), id))
+	// once registered, events are forwarded
+	var verified []string
+	a.RegisterCallbacks(context.TODO(), domain.Callbacks{
+		VerifyObject: func(_ context.Context, _ domain.KindName, checksum string) error {
+			verified = append(verified, checksum)
+			return nil
+		},
+	})
+	assert.NoError(t, callbacks.VerifyObject(context.TODO(), id, "checksum"))
+	assert.NoError(t, callbacks.DeleteObject(context.TODO(), id))
+	assert.Equal(t, []string{"checksum"}, verified)
+}
+
+func TestAdapter_GetClientByKind(t *testing.T) {
+	a := NewInClusterAdapter(config.InCluster{}, fake.NewSimpleDynamicClient(runtime.NewScheme()))
+	id := domain.KindName{Kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, Name: "test"}
+	// unconfigured kinds are read-only, refusals are reported without callbacks registered
+	err := a.PutObject(context.TODO(), id, []byte(`{}`))
+	assert.ErrorIs(t, err, errWriteNotAllowed)
+}
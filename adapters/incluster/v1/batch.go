@@ -0,0 +1,200 @@
+package incluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"go.uber.org/multierr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxBatchPasses limits how many times the writes of a batch failing because of a missing dependency are retried
+const maxBatchPasses = 3
+
+var namespacesResource = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// dependency ranks, objects are applied by increasing rank and deleted by decreasing rank
+const (
+	rankNamespace = iota
+	rankCRD
+	rankClusterScoped
+	rankNamespaced
+)
+
+// batchWrite is a write from a backend batch, it can be retried once its dependencies exist.
+type batchWrite struct {
+	id    domain.KindName
+	rank  int
+	write func(ctx context.Context) error
+}
+
+// dependencyRank returns the rank of an object, namespaces and CRDs come first as other objects depend on them.
+func dependencyRank(id domain.KindName) int {
+	switch {
+	case id.Kind.Group == "" && id.Kind.Resource == "namespaces":
+		return rankNamespace
+	case id.Kind.Group == "apiextensions.k8s.io" && id.Kind.Resource == "customresourcedefinitions":
+		return rankCRD
+	case id.Namespace == "":
+		return rankClusterScoped
+	}
+	return rankNamespaced
+}
+
+// isMissingDependency returns true if a write failed because an object it depends on does not exist yet.
+func isMissingDependency(err error) bool {
+	return apierrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+// batchKindName returns the identifier of a batch item, items without a kind belong to the kind of the batch.
+func batchKindName(kind domain.Kind, itemKind *domain.Kind, name, namespace string, resourceVersion int, uid string) domain.KindName {
+	if itemKind == nil {
+		itemKind = &kind
+	}
+	return domain.KindName{
+		Kind:            itemKind,
+		Name:            name,
+		Namespace:       namespace,
+		ResourceVersion: resourceVersion,
+		UID:             uid,
+	}
+}
+
+// orderedBatch processes a batch from the backend, whose items can be of different kinds.
+// Gets and checksums are handled first, then deletes from dependents to dependencies,
+// then patches and puts from dependencies to dependents. Writes failing because of a missing
+// dependency are retried after the other writes of the batch.
+func (a *Adapter) orderedBatch(ctx context.Context, kind domain.Kind, items domain.BatchItems) error {
+	var err error
+	for _, item := range items.GetObject {
+		id := batchKindName(kind, item.Kind, item.Name, item.Namespace, item.ResourceVersion, item.UID)
+		err = multierr.Append(err, a.GetClientByKind(*id.Kind).GetObject(ctx, id, []byte(item.BaseObject)))
+	}
+	for _, item := range items.NewChecksum {
+		id := batchKindName(kind, item.Kind, item.Name, item.Namespace, item.ResourceVersion, item.UID)
+		err = multierr.Append(err, a.GetClientByKind(*id.Kind).VerifyObject(ctx, id, item.Checksum))
+	}
+
+	var deletes, applies []batchWrite
+	for _, item := range items.ObjectDeleted {
+		id := batchKindName(kind, item.Kind, item.Name, item.Namespace, item.ResourceVersion, item.UID)
+		deletes = append(deletes, batchWrite{id: id, rank: dependencyRank(id), write: func(ctx context.Context) error {
+			return a.GetClientByKind(*id.Kind).DeleteObject(ctx, id)
+		}})
+	}
+	for _, item := range items.PatchObject {
+		item := item
+		id := batchKindName(kind, item.Kind, item.Name, item.Namespace, item.ResourceVersion, item.UID)
+		applies = append(applies, batchWrite{id: id, rank: dependencyRank(id), write: func(ctx context.Context) error {
+			return a.GetClientByKind(*id.Kind).PatchObject(ctx, id, item.Checksum, item.PatchType, []byte(item.Patch))
+		}})
+	}
+	for _, item := range items.PutObject {
+		item := item
+		id := batchKindName(kind, item.Kind, item.Name, item.Namespace, item.ResourceVersion, item.UID)
+		applies = append(applies, batchWrite{id: id, rank: dependencyRank(id), write: func(ctx context.Context) error {
+			return a.GetClientByKind(*id.Kind).PutObject(ctx, id, []byte(item.Object))
+		}})
+	}
+	sort.SliceStable(deletes, func(i, j int) bool { return deletes[i].rank > deletes[j].rank })
+	sort.SliceStable(applies, func(i, j int) bool { return applies[i].rank < applies[j].rank })
+
+	if a.cfg.CreateNamespaces {
+		a.createMissingNamespaces(ctx, applies)
+	}
+	return multierr.Combine(err, a.runBatchWrites(ctx, deletes), a.runBatchWrites(ctx, applies))
+}
+
+// runBatchWrites runs the writes in order, retrying those failing because of a missing dependency
+// as long as other writes succeed. It returns the errors of the writes that never succeeded.
+func (a *Adapter) runBatchWrites(ctx context.Context, writes []batchWrite) error {
+	var failed error // writes failing for another reason than a missing dependency are not retried
+	var retryErr error
+	for pass := 1; pass <= maxBatchPasses && len(writes) > 0; pass++ {
+		var retries []batchWrite
+		retryErr = nil
+		for _, w := range writes {
+			writeErr := w.write(ctx)
+			switch {
+			case writeErr == nil:
+			case isMissingDependency(writeErr):
+				retries = append(retries, w)
+				retryErr = multierr.Append(retryErr, fmt.Errorf("%s: %w", w.id.String(), writeErr))
+			default:
+				failed = multierr.Append(failed, fmt.Errorf("%s: %w", w.id.String(), writeErr))
+			}
+		}
+		if len(retries) == 0 || len(retries) == len(writes) {
+			// done, or no progress was made that could resolve the missing dependencies
+			break
+		}
+		logger.L().Ctx(ctx).Info("retrying batch writes with missing dependencies", helpers.Int("writes", len(retries)), helpers.Int("pass", pass+1))
+		writes = retries
+	}
+	return multierr.Append(failed, retryErr)
+}
+
+// createMissingNamespaces creates the namespaces of the objects applied to writable resources that
+// do not exist yet, except those created by the batch itself. They are labelled as created from the backend.
+// Namespaces must be configured as a writable resource, refused creations are reported to the backend.
+func (a *Adapter) createMissingNamespaces(ctx context.Context, applies []batchWrite) {
+	nsKind := domain.Kind{Group: namespacesResource.Group, Version: namespacesResource.Version, Resource: namespacesResource.Resource}
+	seen := map[string]bool{}
+	for _, w := range applies {
+		if dependencyRank(w.id) == rankNamespace {
+			seen[w.id.Name] = true
+		}
+	}
+	for _, w := range applies {
+		namespace := w.id.Namespace
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		client, ok := a.GetClientByKind(*w.id.Kind).(*Client)
+		if !ok || !client.direction.Writable() {
+			// the write itself is refused, do not create its namespace
+			continue
+		}
+		seen[namespace] = true
+		nsClient, ok := a.GetClientByKind(nsKind).(*Client)
+		if !ok {
+			continue
+		}
+		nsId := domain.KindName{Kind: &nsKind, Name: namespace}
+		if err := nsClient.checkWritable(ctx, nsId, nil); err != nil {
+			continue
+		}
+		_, err := nsClient.client.Resource(nsClient.res).Get(ctx, namespace, metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			continue
+		}
+		if client.dryRun || nsClient.dryRun {
+			logger.L().Ctx(ctx).Info("dry-run: namespace would be created", helpers.String("namespace", namespace))
+			continue
+		}
+		ns := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]interface{}{
+				"name": namespace,
+				"labels": map[string]interface{}{
+					ManagedAccountLabel: a.cfg.Account,
+					ManagedSourceLabel:  ManagedSourceBackend,
+				},
+			},
+		}}
+		if _, err := nsClient.client.Resource(nsClient.res).Create(ctx, ns, metav1.CreateOptions{FieldManager: a.cfg.GetFieldManager()}); err != nil && !apierrors.IsAlreadyExists(err) {
+			logger.L().Ctx(ctx).Warning("cannot create missing namespace", helpers.Error(err), helpers.String("namespace", namespace))
+			continue
+		}
+		logger.L().Ctx(ctx).Info("created missing namespace", helpers.String("namespace", namespace))
+	}
+}
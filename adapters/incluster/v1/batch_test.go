@@ -0,0 +1,286 @@
+package incluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kubescape/synchronizer/config"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func Test_dependencyRank(t *testing.T) {
+	tests := []struct {
+		name string
+		id   domain.KindName
+		want int
+	}{
+		{
+			name: "namespace",
+			id:   domain.KindName{Kind: &domain.Kind{Version: "v1", Resource: "namespaces"}, Name: "ns"},
+			want: rankNamespace,
+		},
+		{
+			name: "crd",
+			id:   domain.KindName{Kind: &domain.Kind{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}, Name: "crd"},
+			want: rankCRD,
+		},
+		{
+			name: "cluster-scoped",
+			id:   domain.KindName{Kind: &domain.Kind{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}, Name: "role"},
+			want: rankClusterScoped,
+		},
+		{
+			name: "namespaced",
+			id:   domain.KindName{Kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, Name: "deploy", Namespace: "ns"},
+			want: rankNamespaced,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dependencyRank(tt.id))
+		})
+	}
+}
+
+func TestAdapter_runBatchWrites(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "ns")
+	tests := []struct {
+		name      string
+		failures  map[string]int // number of times each write fails with a missing dependency
+		other     map[string]bool
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "all succeed",
+			wantCalls: []string{"a", "b", "c"},
+		},
+		{
+			name:      "missing dependency is retried",
+			failures:  map[string]int{"a": 1},
+			wantCalls: []string{"a", "b", "c", "a"},
+		},
+		{
+			name:      "all dependencies missing",
+			failures:  map[string]int{"a": 10, "b": 10, "c": 10},
+			wantCalls: []string{"a", "b", "c"},
+			wantErr:   true,
+		},
+		{
+			name:      "retries stop without progress",
+			failures:  map[string]int{"a": 10},
+			wantCalls: []string{"a", "b", "c", "a"},
+			wantErr:   true,
+		},
+		{
+			name:      "other errors are not retried",
+			other:     map[string]bool{"a": true},
+			wantCalls: []string{"a", "b", "c"},
+			wantErr:   true,
+		},
+		{
+			name:      "other errors are kept when retries succeed",
+			failures:  map[string]int{"b": 1},
+			other:     map[string]bool{"a": true},
+			wantCalls: []string{"a", "b", "c", "b"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var writes []batchWrite
+			for _, name := range []string{"a", "b", "c"} {
+				name := name
+				writes = append(writes, batchWrite{id: domain.KindName{Name: name}, write: func(context.Context) error {
+					calls = append(calls, name)
+					if tt.other[name] {
+						return errors.New("other")
+					}
+					if tt.failures[name] > 0 {
+						tt.failures[name]--
+						return notFound
+					}
+					return nil
+				}})
+			}
+			a := &Adapter{}
+			err := a.runBatchWrites(context.TODO(), writes)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestAdapter_createMissingNamespaces(t *testing.T) {
+	tests := []struct {
+		name        string
+		direction   domain.Direction
+		nsDirection domain.Direction
+		dryRun      bool
+		wantCreated bool
+		wantRefused bool
+	}{
+		{
+			name:        "writable",
+			direction:   domain.BackendToCluster,
+			nsDirection: domain.BackendToCluster,
+			wantCreated: true,
+		},
+		{
+			name:        "dry-run",
+			direction:   domain.BackendToCluster,
+			nsDirection: domain.BackendToCluster,
+			dryRun:      true,
+		},
+		{
+			name:        "not writable",
+			direction:   domain.ClusterToBackend,
+			nsDirection: domain.BackendToCluster,
+		},
+		{
+			name:        "namespaces not writable",
+			direction:   domain.BackendToCluster,
+			nsDirection: domain.ClusterToBackend,
+			wantRefused: true,
+		},
+		{
+			name:        "namespaces not configured",
+			direction:   domain.BackendToCluster,
+			wantRefused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			cfg := config.InCluster{Account: "account-1", CreateNamespaces: true}
+			a := NewInClusterAdapter(cfg, client)
+			var refused []string
+			a.RegisterCallbacks(context.TODO(), domain.Callbacks{
+				WriteFailed: func(_ context.Context, id domain.KindName, reason domain.WriteFailureReason, _ string) error {
+					refused = append(refused, id.Kind.Resource+"/"+id.Name)
+					assert.Equal(t, domain.WriteRefused, reason)
+					return nil
+				},
+			})
+			kind := &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}
+			a.clients[kind.String()] = NewClient(client, cfg, config.Resource{
+				Group:     kind.Group,
+				Version:   kind.Version,
+				Resource:  kind.Resource,
+				Strategy:  domain.CopyStrategy,
+				Direction: tt.direction,
+				DryRun:    tt.dryRun,
+			}, a.shadowObjects)
+			if tt.nsDirection != "" {
+				nsKind := &domain.Kind{Version: "v1", Resource: "namespaces"}
+				a.clients[nsKind.String()] = NewClient(client, cfg, config.Resource{
+					Version:   nsKind.Version,
+					Resource:  nsKind.Resource,
+					Strategy:  domain.CopyStrategy,
+					Direction: tt.nsDirection,
+				}, a.shadowObjects)
+			}
+			for _, c := range a.clients {
+				c.RegisterCallbacks(context.TODO(), a.clientCallbacks())
+			}
+			id := domain.KindName{Kind: kind, Name: "deploy", Namespace: "ns"}
+			a.createMissingNamespaces(context.TODO(), []batchWrite{{id: id, rank: dependencyRank(id)}})
+			var created bool
+			for _, action := range client.Actions() {
+				created = created || action.Matches("create", "namespaces")
+			}
+			assert.Equal(t, tt.wantCreated, created)
+			if tt.wantRefused {
+				assert.Equal(t, []string{"namespaces/ns"}, refused)
+			} else {
+				assert.Empty(t, refused)
+			}
+		})
+	}
+}
+
+func TestAdapter_orderedBatch(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	var applied []string
+	crdApplied := false
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		resource := action.GetResource().Resource
+		applied = append(applied, resource+"/"+patch.GetName())
+		switch resource {
+		case "customresourcedefinitions":
+			crdApplied = true
+		case "widgets":
+			if !crdApplied {
+				return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), patch.GetName())
+			}
+		}
+		obj := &unstructured.Unstructured{}
+		_ = obj.UnmarshalJSON(patch.GetPatch())
+		return true, obj, nil
+	})
+	cfg := config.InCluster{Account: "account-1"}
+	a := NewInClusterAdapter(cfg, client)
+	kinds := map[string]*domain.Kind{
+		"namespaces":                &domain.Kind{Version: "v1", Resource: "namespaces"},
+		"customresourcedefinitions": &domain.Kind{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"},
+		"widgets":                   &domain.Kind{Group: "example.com", Version: "v1", Resource: "widgets"},
+	}
+	for _, kind := range kinds {
+		a.clients[kind.String()] = NewClient(client, cfg, config.Resource{
+			Group:     kind.Group,
+			Version:   kind.Version,
+			Resource:  kind.Resource,
+			Strategy:  domain.CopyStrategy,
+			Direction: domain.BackendToCluster,
+		}, a.shadowObjects)
+	}
+	// dependents come first in the batch
+	items := domain.BatchItems{PutObject: []domain.PutObject{
+		{Kind: kinds["widgets"], Name: "widget", Namespace: "ns", Object: `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"widget","namespace":"ns"}}`},
+		{Kind: kinds["customresourcedefinitions"], Name: "widgets.example.com", Object: `{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition","metadata":{"name":"widgets.example.com"}}`},
+		{Kind: kinds["namespaces"], Name: "ns", Object: `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns"}}`},
+	}}
+	err := a.Batch(context.TODO(), *kinds["widgets"], domain.DefaultBatch, items)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"namespaces/ns", "customresourcedefinitions/widgets.example.com", "widgets/widget"}, applied)
+}
+
+func TestAdapter_orderedBatch_deletePreconditions(t *testing.T) {
+	fakeClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	fakeClient.PrependReactor("delete", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	recorded := &recordedOptions{}
+	client := recordingClient{Interface: fakeClient, recorded: recorded}
+	cfg := config.InCluster{}
+	a := NewInClusterAdapter(cfg, client)
+	kind := &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}
+	a.clients[kind.String()] = NewClient(client, cfg, config.Resource{
+		Group:     kind.Group,
+		Version:   kind.Version,
+		Resource:  kind.Resource,
+		Strategy:  domain.CopyStrategy,
+		Direction: domain.BackendToCluster,
+	}, a.shadowObjects)
+	items := domain.BatchItems{ObjectDeleted: []domain.ObjectDeleted{
+		{Name: "deploy", Namespace: "ns", ResourceVersion: 42, UID: "uid-1"},
+	}}
+	err := a.Batch(context.TODO(), *kind, domain.DefaultBatch, items)
+	assert.NoError(t, err)
+	require.Len(t, recorded.deletes, 1)
+	assert.Equal(t, &metav1.Preconditions{UID: ptr.To(types.UID("uid-1")), ResourceVersion: ptr.To("42")}, recorded.deletes[0].Preconditions)
+}
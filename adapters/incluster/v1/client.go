@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	jsonpatch "github.com/evanphx/json-patch"
@@ -18,13 +21,19 @@ import (
 	"github.com/kubescape/synchronizer/config"
 	"github.com/kubescape/synchronizer/domain"
 	"github.com/kubescape/synchronizer/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
 )
 
+// maxListRestarts limits how many times a paginated list is restarted when its continue token expires
+const maxListRestarts = 3
+
 type BatchProcessingFunc func(context.Context, *Client, domain.BatchItems) error
 
 // resourceVersionGetter is an interface used to get resource version from events.
@@ -39,31 +48,87 @@ type Client struct {
 	kind                *domain.Kind
 	callbacks           domain.Callbacks
 	res                 schema.GroupVersionResource
-	ShadowObjects       map[string][]byte
+	ShadowObjects       ShadowStore
 	Strategy            domain.Strategy
+	direction           domain.Direction
+	fieldManager        string
+	conflictPolicy      domain.ConflictPolicy
+	recreateOnImmutable bool
+	dryRun              bool
+	syncStatus          bool
+	reconciliationMode  domain.ReconciliationMode
+	pageSize            int64
+	listThenGet         bool
+	fetchConcurrency    int
+	fetchLimiter        *rate.Limiter
+	maxObjectSize       int
+	oversizePolicy      domain.OversizePolicy
+	events              *eventRecorder
+	ignored             mapset.Set[string]
+	prune               bool
+	retries             *writeRetries
+	health              *resourceHealth
+	ignoredFields       []string
+	lastSent            sync.Map // checksums of the objects sent with the copy strategy
 	batchProcessingFunc map[domain.BatchType]BatchProcessingFunc
+	ownership           config.OwnershipRules
+	owners              *ownerResolver
+	cooldown            time.Duration
+	maxDelay            time.Duration
 }
 
-var errWatchClosed = errors.New("watch channel closed")
+var (
+	errWatchClosed     = errors.New("watch channel closed")
+	errWriteNotAllowed = errors.New("write not allowed")
+	// errBaseVersionOutdated is returned when the backend writes an object based on an outdated version
+	errBaseVersionOutdated = errors.New("base version outdated")
+)
 
-func NewClient(client dynamic.Interface, account, cluster string, r config.Resource) *Client {
+func NewClient(client dynamic.Interface, cfg config.InCluster, r config.Resource, shadowObjects ShadowStore) *Client {
 	res := schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+	var events *eventRecorder
+	if cfg.Events != nil && cfg.Events.Enabled {
+		events = newEventRecorder(client, cfg.Account, time.Duration(cfg.Events.GetMinIntervalSeconds())*time.Second)
+	}
 	return &Client{
-		account: account,
+		account: cfg.Account,
 		client:  client,
-		cluster: cluster,
+		cluster: cfg.ClusterName,
 		kind: &domain.Kind{
 			Group:    res.Group,
 			Version:  res.Version,
 			Resource: res.Resource,
 		},
-		res:           res,
-		ShadowObjects: map[string][]byte{},
-		Strategy:      r.Strategy,
+		res:                 res,
+		ShadowObjects:       shadowObjects,
+		Strategy:            r.Strategy,
+		direction:           r.Direction,
+		fieldManager:        cfg.GetFieldManager(),
+		conflictPolicy:      r.ConflictPolicy,
+		recreateOnImmutable: r.RecreateOnImmutable,
+		dryRun:              cfg.DryRun || r.DryRun,
+		syncStatus:          r.SyncStatus,
+		reconciliationMode:  r.ReconciliationMode,
+		pageSize:            int64(cfg.GetListPageSize()),
+		listThenGet:         r.GetListThenGet(),
+		fetchConcurrency:    r.GetFetchConcurrency(),
+		fetchLimiter:        rate.NewLimiter(rate.Limit(r.GetFetchQPS()), r.GetFetchConcurrency()),
+		maxObjectSize:       r.MaxObjectSizeBytes,
+		oversizePolicy:      r.OversizePolicy,
+		events:              events,
+		ignored:             mapset.NewSet[string](),
+		prune:               r.Prune,
+		retries:             newWriteRetries(r.GetMaxWriteAttempts(), newRetryLimiter()),
+		health:              newResourceHealth(),
+		ignoredFields:       append(append([]string{}, alwaysIgnoredFields...), r.IgnoredFields...),
+		cooldown:            time.Duration(r.CooldownSeconds) * time.Second,
+		maxDelay:            time.Duration(r.MaxDelaySeconds) * time.Second,
 		batchProcessingFunc: map[domain.BatchType]BatchProcessingFunc{
-			domain.DefaultBatch:        defaultBatchProcessingFunc, // regular processing, when batch type is not set
+			// default batches are ordered across kinds by the adapter
 			domain.ReconciliationBatch: reconcileBatchProcessingFunc,
 		},
+		ownership: r.OwnershipRules(),
+		owners:    newOwnerResolver(client, nil),
 	}
 }
 
@@ -73,35 +138,38 @@ func (c *Client) Start(ctx context.Context) error {
 	ctx = utils.ContextFromGeneric(ctx, domain.Generic{})
 	logger.L().Info("starting incluster client", helpers.String("resource", c.res.Resource))
 	watchOpts := metav1.ListOptions{}
-	// for our storage, we need to list all resources and get them one by one
+	// for aggregated APIs like our storage, we need to list all resources and get them one by one
 	// as list returns objects with empty spec
 	// and watch does not return existing objects
-	if c.res.Group == "spdx.softwarecomposition.kubescape.io" {
+	if c.listThenGet {
 		if err := backoff.RetryNotify(func() error {
 			var err error
-			watchOpts.ResourceVersion, err = c.getExistingStorageObjects(ctx)
+			watchOpts.ResourceVersion, err = c.getExistingObjects(ctx)
 			return err
-		}, utils.NewBackOff(), func(err error, d time.Duration) {
-			logger.L().Ctx(ctx).Warning("get existing storage objects", helpers.Error(err),
+		}, c.newBackOff(ctx), func(err error, d time.Duration) {
+			c.markFailure(ctx, err)
+			logger.L().Ctx(ctx).Warning("get existing objects", helpers.Error(err),
 				helpers.String("resource", c.res.Resource),
 				helpers.String("retry in", d.String()))
 		}); err != nil {
-			return fmt.Errorf("giving up get existing storage objects: %w", err)
+			return fmt.Errorf("giving up get existing objects: %w", err)
 		}
 	}
 	// begin watch
-	eventQueue := utils.NewCooldownQueue()
+	eventQueue := utils.NewCooldownQueueWithParams(c.cooldown, c.maxDelay)
 	go c.watchRetry(ctx, watchOpts, eventQueue)
 	// process events
 	for event := range eventQueue.ResultChan {
+		cooldownEmittedCounter.WithLabelValues(c.res.Resource).Inc()
+		cooldownCoalescingRatio.WithLabelValues(c.res.Resource).Set(eventQueue.CoalescingRatio())
 		// skip non-objects
 		d, ok := event.Object.(*unstructured.Unstructured)
 		if !ok {
 			continue
 		}
 
-		// skip non-standalone resources
-		if hasParent(d) {
+		// skip non-standalone resources, unless they are sent with a reference to their owner
+		if c.isSkippedChild(d) {
 			continue
 		}
 		id := domain.KindName{
@@ -109,12 +177,19 @@ func (c *Client) Start(ctx context.Context) error {
 			Name:            d.GetName(),
 			Namespace:       d.GetNamespace(),
 			ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+			UID:             string(d.GetUID()),
+		}
+		// skip objects excluded from sync
+		if isIgnored(d) {
+			c.handleIgnored(ctx, id, event.Type)
+			continue
 		}
+		c.ignored.Remove(id.String())
 
 		switch {
 		case event.Type == watch.Added:
 			logger.L().Debug("added resource", helpers.String("id", id.String()))
-			newObject, err := c.getObjectFromUnstructured(d)
+			newObject, err := c.getObjectFromUnstructured(ctx, d)
 			if err != nil {
 				logger.L().Ctx(ctx).Error("cannot get object", helpers.Error(err), helpers.String("id", id.String()))
 				continue
@@ -122,28 +197,37 @@ func (c *Client) Start(ctx context.Context) error {
 			err = c.callVerifyObject(ctx, id, newObject)
 			if err != nil {
 				logger.L().Ctx(ctx).Error("cannot handle added resource", helpers.Error(err), helpers.String("id", id.String()))
+				continue
 			}
+			c.markSent(id, newObject)
 		case event.Type == watch.Deleted:
 			logger.L().Debug("deleted resource", helpers.String("id", id.String()))
 			err := c.callbacks.DeleteObject(ctx, id)
 			if err != nil {
 				logger.L().Ctx(ctx).Error("cannot handle deleted resource", helpers.Error(err), helpers.String("id", id.String()))
 			}
-			if c.Strategy == domain.PatchStrategy {
-				// remove from known resources
-				delete(c.ShadowObjects, id.String())
-			}
+			// remove from known resources
+			c.ShadowObjects.Delete(id.String())
+			c.forgetSent(id)
 		case event.Type == watch.Modified:
 			logger.L().Debug("modified resource", helpers.String("id", id.String()))
-			newObject, err := c.getObjectFromUnstructured(d)
+			newObject, err := c.getObjectFromUnstructured(ctx, d)
 			if err != nil {
 				logger.L().Ctx(ctx).Error("cannot get object", helpers.Error(err), helpers.String("id", id.String()))
 				continue
 			}
+			if c.isUnchanged(ctx, id, newObject) {
+				// only ignored fields changed
+				suppressedCounter.WithLabelValues(c.res.Resource).Inc()
+				logger.L().Debug("unchanged resource, skipping", helpers.String("id", id.String()))
+				continue
+			}
 			err = c.callPutOrPatch(ctx, id, nil, newObject)
 			if err != nil {
 				logger.L().Ctx(ctx).Error("cannot handle modified resource", helpers.Error(err), helpers.String("id", id.String()))
+				continue
 			}
+			c.markSent(id, newObject)
 		}
 	}
 	return nil
@@ -154,6 +238,9 @@ func (c *Client) IsRelated(ctx context.Context, id domain.ClientIdentifier) bool
 }
 
 func (c *Client) Stop(_ context.Context) error {
+	if c.retries != nil {
+		c.retries.queue.ShutDown()
+	}
 	return nil
 }
 
@@ -164,6 +251,7 @@ func (c *Client) watchRetry(ctx context.Context, watchOpts metav1.ListOptions, e
 			return fmt.Errorf("client resource: %w", err)
 		}
 		logger.L().Info("starting watch", helpers.String("resource", c.res.Resource))
+		c.markHealthy(ctx)
 		for {
 			event, chanActive := <-watcher.ResultChan()
 			// set resource version to resume watch from
@@ -180,66 +268,67 @@ func (c *Client) watchRetry(ctx context.Context, watchOpts metav1.ListOptions, e
 				return errWatchClosed
 			}
 			if event.Type == watch.Error {
-				return fmt.Errorf("watch error: %s", event.Object)
+				return fmt.Errorf("watch error: %w", apierrors.FromObject(event.Object))
 			}
+			cooldownEnqueuedCounter.WithLabelValues(c.res.Resource).Inc()
 			eventQueue.Enqueue(event)
 		}
-	}, utils.NewBackOff(), func(err error, d time.Duration) {
+	}, c.newBackOff(ctx), func(err error, d time.Duration) {
 		if !errors.Is(err, errWatchClosed) {
+			c.markFailure(ctx, err)
 			logger.L().Ctx(ctx).Warning("watch", helpers.Error(err),
 				helpers.String("resource", c.res.Resource),
 				helpers.String("retry in", d.String()))
 		}
-	}); err != nil {
-		logger.L().Ctx(ctx).Fatal("giving up watch", helpers.Error(err),
+	}); err != nil && ctx.Err() == nil && !eventQueue.Closed() {
+		// only this resource stops syncing
+		c.setState(ctx, domain.ResourceDegraded, err.Error())
+		logger.L().Ctx(ctx).Error("giving up watch", helpers.Error(err),
 			helpers.String("resource", c.res.Resource))
 	}
 }
 
-// hasParent returns true if workload has a parent
-// based on https://github.com/kubescape/k8s-interface/blob/2855cc94bd7666b227ad9e5db5ca25cb895e6cee/k8sinterface/k8sdynamic.go#L219
-func hasParent(workload *unstructured.Unstructured) bool {
-	if workload == nil {
-		return false
-	}
-	// filter out non-controller workloads
-	if !slices.Contains([]string{"Pod", "Job", "ReplicaSet"}, workload.GetKind()) {
-		return false
-	}
-	// check if workload has owner
-	ownerReferences := workload.GetOwnerReferences() // OwnerReferences in workload
-	if len(ownerReferences) > 0 {
-		return slices.Contains([]string{"apps/v1", "batch/v1", "batch/v1beta1"}, ownerReferences[0].APIVersion)
-	}
-	// check if workload is Pod with pod-template-hash label
-	if workload.GetKind() == "Pod" {
-		if podLabels := workload.GetLabels(); podLabels != nil {
-			if podHash, ok := podLabels["pod-template-hash"]; ok && podHash != "" {
-				return true
-			}
+// isSkippedChild returns true if the object is a child that should not be sent on its own.
+func (c *Client) isSkippedChild(d *unstructured.Unstructured) bool {
+	return !c.ownership.SendChildren && hasParent(d, c.ownership)
+}
+
+// filterAndMarshal removes noisy fields from the object and marshals it,
+// children are annotated with their top-level owner when they are sent,
+// and objects larger than the maximum size are handled according to the oversize policy.
+func (c *Client) filterAndMarshal(ctx context.Context, d *unstructured.Unstructured) ([]byte, error) {
+	if c.ownership.SendChildren && hasParent(d, c.ownership) {
+		if err := c.owners.annotateTopLevelOwner(ctx, d); err != nil {
+			return nil, fmt.Errorf("annotate top-level owner: %w", err)
 		}
 	}
-	return false
+	c.removeManagedLabels(d)
+	object, err := utils.FilterAndMarshal(d)
+	if err != nil || c.maxObjectSize <= 0 || len(object) <= c.maxObjectSize {
+		return object, err
+	}
+	return c.handleOversize(ctx, d, len(object))
 }
 
 func (c *Client) callPutOrPatch(ctx context.Context, id domain.KindName, baseObject []byte, newObject []byte) error {
-	if c.Strategy == domain.PatchStrategy {
+	if c.Strategy.Patches() {
 		if len(baseObject) > 0 {
 			// update reference object
-			c.ShadowObjects[id.String()] = baseObject
+			c.ShadowObjects.Set(id.String(), baseObject)
 		}
-		if oldObject, ok := c.ShadowObjects[id.String()]; ok {
+		if oldObject, ok := c.ShadowObjects.Get(id.String()); ok {
 			// calculate checksum
 			checksum, err := utils.CanonicalHash(newObject)
 			if err != nil {
 				return fmt.Errorf("calculate checksum: %w", err)
 			}
 			// calculate patch
-			patch, err := jsonpatch.CreateMergePatch(oldObject, newObject)
+			patchType := c.Strategy.PatchType()
+			patch, err := utils.CreatePatch(patchType, oldObject, newObject)
 			if err != nil {
-				return fmt.Errorf("create merge patch: %w", err)
+				return fmt.Errorf("create patch: %w", err)
 			}
-			err = c.callbacks.PatchObject(ctx, id, checksum, patch)
+			err = c.callbacks.PatchObject(ctx, id, checksum, patchType, patch)
 			if err != nil {
 				return fmt.Errorf("send patch object: %w", err)
 			}
@@ -250,7 +339,7 @@ func (c *Client) callPutOrPatch(ctx context.Context, id domain.KindName, baseObj
 			}
 		}
 		// add/update known resources
-		c.ShadowObjects[id.String()] = newObject
+		c.ShadowObjects.Set(id.String(), newObject)
 	} else {
 		err := c.callbacks.PutObject(ctx, id, newObject)
 		if err != nil {
@@ -273,12 +362,78 @@ func (c *Client) callVerifyObject(ctx context.Context, id domain.KindName, objec
 	return nil
 }
 
-func (c *Client) DeleteObject(_ context.Context, id domain.KindName) error {
-	if c.Strategy == domain.PatchStrategy {
-		// remove from known resources
-		delete(c.ShadowObjects, id.String())
+// checkWritable refuses writes from the backend into resources that are not writable,
+// or into live objects excluded from sync, and reports them to the backend. A nil live object does not exist,
+// writes check it first without the live object, so that non-writable resources are refused without reading the cluster.
+func (c *Client) checkWritable(ctx context.Context, id domain.KindName, live *unstructured.Unstructured) error {
+	var err error
+	switch {
+	case !c.direction.Writable():
+		err = fmt.Errorf("%w: resource %s is not configured as writable", errWriteNotAllowed, c.kind.String())
+	case live != nil && isIgnored(live):
+		err = fmt.Errorf("%w: object %s is marked with %s", errWriteNotAllowed, id.String(), IgnoreKey)
+	default:
+		return nil
+	}
+	writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueRefused).Inc()
+	logger.L().Ctx(ctx).Warning("refusing write from backend", helpers.Error(err), helpers.String("id", id.String()))
+	if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteRefused, err.Error()); reportErr != nil {
+		logger.L().Ctx(ctx).Error("cannot report refused write", helpers.Error(reportErr), helpers.String("id", id.String()))
+	}
+	return err
+}
+
+// getLiveObject returns the object currently in the cluster, or nil if it does not exist.
+func (c *Client) getLiveObject(id domain.KindName) (*unstructured.Unstructured, error) {
+	live, err := c.client.Resource(c.res).Namespace(id.Namespace).Get(context.Background(), id.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return live, err
+}
+
+func (c *Client) DeleteObject(ctx context.Context, id domain.KindName) error {
+	c.cancelRetry(id)
+	if err := c.checkWritable(ctx, id, nil); err != nil {
+		return err
+	}
+	live, err := c.getLiveObject(id)
+	if err != nil {
+		// the delete will most likely fail as well, let it report the error
+		logger.L().Ctx(ctx).Warning("cannot check if object is ignored", helpers.Error(err), helpers.String("id", id.String()))
 	}
-	return c.client.Resource(c.res).Namespace(id.Namespace).Delete(context.Background(), id.Name, metav1.DeleteOptions{})
+	if err := c.checkWritable(ctx, id, live); err != nil {
+		return err
+	}
+	if c.dryRun {
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueDryRun).Inc()
+		logger.L().Ctx(ctx).Info("dry-run: object would be deleted", helpers.String("id", id.String()))
+		return nil
+	}
+	// remove from known resources
+	c.ShadowObjects.Delete(id.String())
+	err = c.client.Resource(c.res).Namespace(id.Namespace).Delete(context.Background(), id.Name, metav1.DeleteOptions{Preconditions: deletePreconditions(id)})
+	if apierrors.IsConflict(err) {
+		// the object was recreated or modified since the backend has seen it
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflict).Inc()
+		if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteConflict, err.Error()); reportErr != nil {
+			logger.L().Ctx(ctx).Error("cannot report delete conflict", helpers.Error(reportErr), helpers.String("id", id.String()))
+		}
+		return fmt.Errorf("delete resource: %w", err)
+	}
+	if err != nil {
+		c.retryOnFailure(ctx, id, err, func(ctx context.Context) error {
+			err := c.client.Resource(c.res).Namespace(id.Namespace).Delete(context.Background(), id.Name, metav1.DeleteOptions{Preconditions: deletePreconditions(id)})
+			if err == nil || apierrors.IsNotFound(err) {
+				c.events.recordDelete(ctx, id)
+				return nil
+			}
+			return err
+		})
+		return err
+	}
+	c.events.recordDelete(ctx, id)
+	return nil
 }
 
 func (c *Client) GetObject(ctx context.Context, id domain.KindName, baseObject []byte) error {
@@ -286,15 +441,37 @@ func (c *Client) GetObject(ctx context.Context, id domain.KindName, baseObject [
 	if err != nil {
 		return fmt.Errorf("get resource: %w", err)
 	}
-	newObject, err := utils.FilterAndMarshal(obj)
+	newObject, err := c.filterAndMarshal(ctx, obj)
 	if err != nil {
 		return fmt.Errorf("marshal resource: %w", err)
 	}
-	return c.callPutOrPatch(ctx, id, baseObject, newObject)
+	if err := c.callPutOrPatch(ctx, id, baseObject, newObject); err != nil {
+		return err
+	}
+	c.markSent(id, newObject)
+	return nil
 }
 
-func (c *Client) PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
-	baseObject, err := c.patchObject(ctx, id, checksum, patch)
+func (c *Client) PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
+	c.cancelRetry(id)
+	baseObject, err := c.patchObject(ctx, id, checksum, patchType, patch)
+	if errors.Is(err, errWriteNotAllowed) {
+		return err
+	}
+	if isRetriable(err) {
+		c.retryOnFailure(ctx, id, err, func(ctx context.Context) error {
+			baseObject, err := c.patchObject(ctx, id, checksum, patchType, patch)
+			if err != nil && !isRetriable(err) && !errors.Is(err, errWriteNotAllowed) {
+				// the base of the patch is stale, stop retrying and ask for the full object
+				logger.L().Ctx(ctx).Warning("patch object after retry, sending get object", helpers.Error(err), helpers.String("id", id.String()))
+				if getErr := c.callbacks.GetObject(ctx, id, baseObject); getErr != nil {
+					logger.L().Ctx(ctx).Error("cannot send get object", helpers.Error(getErr), helpers.String("id", id.String()))
+				}
+			}
+			return err
+		})
+		return err
+	}
 	if err != nil {
 		logger.L().Ctx(ctx).Warning("patch object, sending get object", helpers.Error(err), helpers.String("id", id.String()))
 		return c.callbacks.GetObject(ctx, id, baseObject)
@@ -302,20 +479,27 @@ func (c *Client) PatchObject(ctx context.Context, id domain.KindName, checksum s
 	return nil
 }
 
-func (c *Client) patchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) ([]byte, error) {
-	if c.Strategy != domain.PatchStrategy {
-		return nil, fmt.Errorf("patch strategy not enabled for resource %s", id.Kind.String())
+func (c *Client) patchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) ([]byte, error) {
+	if err := c.checkWritable(ctx, id, nil); err != nil {
+		return nil, err
 	}
-	obj, err := c.client.Resource(c.res).Namespace(id.Namespace).Get(context.Background(), id.Name, metav1.GetOptions{})
+	live, err := c.client.Resource(c.res).Namespace(id.Namespace).Get(context.Background(), id.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("get resource: %w", err)
 	}
-	object, err := utils.FilterAndMarshal(obj)
+	if err := c.checkWritable(ctx, id, live); err != nil {
+		return nil, err
+	}
+	if !c.Strategy.Patches() {
+		return nil, fmt.Errorf("patch strategy not enabled for resource %s", id.Kind.String())
+	}
+	// the live object is written to below, keep it unfiltered
+	object, err := c.filterAndMarshal(ctx, live.DeepCopy())
 	if err != nil {
 		return nil, fmt.Errorf("marshal resource: %w", err)
 	}
 	// apply patch
-	modified, err := jsonpatch.MergePatch(object, patch)
+	modified, err := utils.ApplyPatch(patchType, object, patch)
 	if err != nil {
 		return object, fmt.Errorf("apply patch: %w", err)
 	}
@@ -327,26 +511,249 @@ func (c *Client) patchObject(ctx context.Context, id domain.KindName, checksum s
 	if newChecksum != checksum {
 		return object, fmt.Errorf("checksum mismatch: %s != %s", newChecksum, checksum)
 	}
-	// update known resources
-	c.ShadowObjects[id.String()] = modified
+	if !c.dryRun {
+		// update known resources
+		c.ShadowObjects.Set(id.String(), modified)
+	}
 	// save object
-	return object, c.PutObject(ctx, id, modified)
+	return object, c.writeObject(ctx, id, modified, operationPatch, live)
+}
+
+func (c *Client) PutObject(ctx context.Context, id domain.KindName, object []byte) error {
+	c.cancelRetry(id)
+	err := c.putObject(ctx, id, object, operationPut)
+	c.retryOnFailure(ctx, id, err, func(ctx context.Context) error {
+		return c.putObject(ctx, id, object, operationPut)
+	})
+	return err
+}
+
+func (c *Client) putObject(ctx context.Context, id domain.KindName, object []byte, operation writeOperation) error {
+	if err := c.checkWritable(ctx, id, nil); err != nil {
+		return err
+	}
+	live, err := c.getLiveObject(id)
+	if err != nil {
+		return fmt.Errorf("get resource: %w", err)
+	}
+	if err := c.checkWritable(ctx, id, live); err != nil {
+		return err
+	}
+	return c.writeObject(ctx, id, object, operation, live)
 }
 
-func (c *Client) PutObject(_ context.Context, id domain.KindName, object []byte) error {
+// writeObject applies an object over the live one, which is nil if it does not exist yet.
+func (c *Client) writeObject(ctx context.Context, id domain.KindName, object []byte, operation writeOperation, live *unstructured.Unstructured) error {
 	var obj unstructured.Unstructured
 	err := obj.UnmarshalJSON(object)
 	if err != nil {
 		return fmt.Errorf("unmarshal object: %w", err)
 	}
-	// use apply to create or update object, we want to overwrite existing objects
-	_, err = c.client.Resource(c.res).Namespace(id.Namespace).Apply(context.Background(), id.Name, &obj, metav1.ApplyOptions{FieldManager: "application/apply-patch"})
-	if err != nil {
+	if err := c.checkBaseVersion(ctx, id, live); err != nil {
+		return err
+	}
+	removeTopLevelOwner(&obj)
+	// only objects created by the synchronizer are labelled, user-created ones must never be pruned
+	if live == nil || c.isManaged(live) {
+		c.setManagedLabels(&obj)
+	}
+	if err := c.apply(ctx, id, &obj, operation, live); err != nil {
+		return err
+	}
+	if c.syncStatus {
+		return c.applyStatus(ctx, id, &obj)
+	}
+	return nil
+}
+
+// checkBaseVersion rejects writes based on an outdated version of the object: when the backend provides the
+// resourceVersion or UID its write is based on, the live object must not be newer nor a different one.
+// Rejected writes are reported to the backend as conflicts. A nil live object does not exist yet.
+func (c *Client) checkBaseVersion(ctx context.Context, id domain.KindName, live *unstructured.Unstructured) error {
+	if live == nil || (id.ResourceVersion == 0 && id.UID == "") {
+		return nil
+	}
+	var conflict error
+	switch {
+	case id.UID != "" && string(live.GetUID()) != id.UID:
+		conflict = fmt.Errorf("%w: object was recreated, live UID %s differs from %s", errBaseVersionOutdated, live.GetUID(), id.UID)
+	case id.ResourceVersion != 0 && domain.ToResourceVersion(live.GetResourceVersion()) > id.ResourceVersion:
+		conflict = fmt.Errorf("%w: live resourceVersion %s is newer than %d", errBaseVersionOutdated, live.GetResourceVersion(), id.ResourceVersion)
+	default:
+		return nil
+	}
+	writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflict).Inc()
+	logger.L().Ctx(ctx).Warning("refusing outdated write from backend", helpers.Error(conflict), helpers.String("id", id.String()))
+	if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteConflict, conflict.Error()); reportErr != nil {
+		logger.L().Ctx(ctx).Error("cannot report outdated write", helpers.Error(reportErr), helpers.String("id", id.String()))
+	}
+	return conflict
+}
+
+// deletePreconditions returns the UID and resourceVersion preconditions provided by the backend, if any.
+func deletePreconditions(id domain.KindName) *metav1.Preconditions {
+	var preconditions metav1.Preconditions
+	if id.UID != "" {
+		preconditions.UID = ptr.To(types.UID(id.UID))
+	}
+	if id.ResourceVersion != 0 {
+		preconditions.ResourceVersion = ptr.To(strconv.Itoa(id.ResourceVersion))
+	}
+	if preconditions.UID == nil && preconditions.ResourceVersion == nil {
+		return nil
+	}
+	return &preconditions
+}
+
+// applyStatus uses server-side apply on the status subresource, as the main apply ignores the status
+// of kinds having one. It follows the same field manager, conflict policy and dry-run settings as apply.
+func (c *Client) applyStatus(ctx context.Context, id domain.KindName, obj *unstructured.Unstructured) error {
+	status, ok := obj.Object["status"]
+	if !ok {
+		return nil
+	}
+	if c.dryRun {
+		// the object was not created by the dry-run apply, so the status cannot be applied
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueDryRun).Inc()
+		logger.L().Ctx(ctx).Info("dry-run: status would be applied", helpers.String("id", id.String()))
+		return nil
+	}
+	// only send the fields we manage in the status
+	statusObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"status":     status,
+	}}
+	statusObj.SetName(obj.GetName())
+	statusObj.SetNamespace(obj.GetNamespace())
+	force := c.conflictPolicy == domain.ConflictForce
+	_, err := c.client.Resource(c.res).Namespace(id.Namespace).ApplyStatus(context.Background(), id.Name, statusObj, metav1.ApplyOptions{FieldManager: c.fieldManager, Force: force})
+	switch {
+	case err == nil:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueStatusApplied).Inc()
+		return nil
+	case apierrors.IsConflict(err) && c.conflictPolicy == domain.ConflictSkip:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflictSkipped).Inc()
+		logger.L().Ctx(ctx).Info("status apply conflict, skipping object", helpers.Error(err), helpers.String("id", id.String()))
+		return nil
+	case apierrors.IsConflict(err):
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflict).Inc()
+		if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteConflict, err.Error()); reportErr != nil {
+			logger.L().Ctx(ctx).Error("cannot report status apply conflict", helpers.Error(reportErr), helpers.String("id", id.String()))
+		}
+		return fmt.Errorf("apply status: %w", err)
+	default:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueError).Inc()
+		return fmt.Errorf("apply status: %w", err)
+	}
+}
+
+// apply uses server-side apply to create or update the object, we want to overwrite existing objects.
+// Conflicts with other field managers are handled according to the conflict policy of the resource.
+// Successful writes are recorded as events on the object. The live object fetched by the write, nil if it
+// does not exist, is used to log the diff of dry-run applies.
+func (c *Client) apply(ctx context.Context, id domain.KindName, obj *unstructured.Unstructured, operation writeOperation, live *unstructured.Unstructured) error {
+	force := c.conflictPolicy == domain.ConflictForce
+	opts := metav1.ApplyOptions{FieldManager: c.fieldManager, Force: force}
+	if c.dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := c.client.Resource(c.res).Namespace(id.Namespace).Apply(context.Background(), id.Name, obj, opts)
+	switch {
+	case err == nil && c.dryRun:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueDryRun).Inc()
+		c.logDryRunDiff(ctx, id, live, applied)
+		return nil
+	case err == nil:
+		if force {
+			writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueForced).Inc()
+		} else {
+			writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueApplied).Inc()
+		}
+		c.events.recordWrite(ctx, id, applied, operation)
+		return nil
+	case apierrors.IsConflict(err) && c.conflictPolicy == domain.ConflictSkip:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflictSkipped).Inc()
+		logger.L().Ctx(ctx).Info("apply conflict, skipping object", helpers.Error(err), helpers.String("id", id.String()))
+		return nil
+	case apierrors.IsConflict(err):
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflict).Inc()
+		if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteConflict, err.Error()); reportErr != nil {
+			logger.L().Ctx(ctx).Error("cannot report apply conflict", helpers.Error(reportErr), helpers.String("id", id.String()))
+		}
 		return fmt.Errorf("apply resource: %w", err)
+	case c.recreateOnImmutable && isImmutableFieldError(err) && c.dryRun:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueDryRun).Inc()
+		logger.L().Ctx(ctx).Info("dry-run: object would be recreated", helpers.Error(err), helpers.String("id", id.String()))
+		return nil
+	case c.recreateOnImmutable && isImmutableFieldError(err):
+		logger.L().Ctx(ctx).Info("immutable field changed, recreating object", helpers.Error(err), helpers.String("id", id.String()))
+		return c.recreate(ctx, id, obj, operation)
+	default:
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueError).Inc()
+		return fmt.Errorf("apply resource: %w", err)
+	}
+}
+
+// logDryRunDiff logs the merge patch between the live object, nil if it does not exist, and the result of a dry-run apply.
+func (c *Client) logDryRunDiff(ctx context.Context, id domain.KindName, live, applied *unstructured.Unstructured) {
+	newObject, err := utils.FilterAndMarshal(applied)
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("dry-run: cannot marshal result", helpers.Error(err), helpers.String("id", id.String()))
+		return
+	}
+	if live == nil {
+		logger.L().Ctx(ctx).Info("dry-run: object would be created", helpers.String("id", id.String()), helpers.String("object", string(newObject)))
+		return
+	}
+	// filtering modifies the object, which is still used by the write
+	oldObject, err := utils.FilterAndMarshal(live.DeepCopy())
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("dry-run: cannot marshal live object", helpers.Error(err), helpers.String("id", id.String()))
+		return
+	}
+	diff, err := jsonpatch.CreateMergePatch(oldObject, newObject)
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("dry-run: cannot compute diff", helpers.Error(err), helpers.String("id", id.String()))
+		return
+	}
+	logger.L().Ctx(ctx).Info("dry-run: object would be updated", helpers.String("id", id.String()), helpers.String("diff", string(diff)))
+}
+
+// recreate deletes the object and creates it again, it is used when immutable fields have changed.
+func (c *Client) recreate(ctx context.Context, id domain.KindName, obj *unstructured.Unstructured, operation writeOperation) error {
+	err := c.client.Resource(c.res).Namespace(id.Namespace).Delete(context.Background(), id.Name, metav1.DeleteOptions{Preconditions: deletePreconditions(id)})
+	if apierrors.IsConflict(err) {
+		// the object was recreated or modified since the backend has seen it
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueConflict).Inc()
+		if reportErr := c.callbacks.WriteFailed(ctx, id, domain.WriteConflict, err.Error()); reportErr != nil {
+			logger.L().Ctx(ctx).Error("cannot report recreate conflict", helpers.Error(reportErr), helpers.String("id", id.String()))
+		}
+		return fmt.Errorf("delete resource before recreate: %w", err)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueError).Inc()
+		return fmt.Errorf("delete resource before recreate: %w", err)
 	}
+	// the object is created from scratch
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	applied, err := c.client.Resource(c.res).Namespace(id.Namespace).Apply(context.Background(), id.Name, obj, metav1.ApplyOptions{FieldManager: c.fieldManager, Force: true})
+	if err != nil {
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueError).Inc()
+		return fmt.Errorf("apply resource after recreate: %w", err)
+	}
+	writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueRecreated).Inc()
+	logger.L().Ctx(ctx).Info("object recreated", helpers.String("id", id.String()))
+	c.events.recordWrite(ctx, id, applied, operation)
 	return nil
 }
 
+// isImmutableFieldError returns true if the error was caused by a change to an immutable field.
+func isImmutableFieldError(err error) bool {
+	return apierrors.IsInvalid(err) && strings.Contains(err.Error(), "field is immutable")
+}
+
 func (c *Client) RegisterCallbacks(_ context.Context, callbacks domain.Callbacks) {
 	c.callbacks = callbacks
 }
@@ -356,7 +763,7 @@ func (c *Client) Callbacks(_ context.Context) (domain.Callbacks, error) {
 }
 
 func (c *Client) VerifyObject(ctx context.Context, id domain.KindName, newChecksum string) error {
-	baseObject, err := c.verifyObject(id, newChecksum)
+	baseObject, err := c.verifyObject(ctx, id, newChecksum)
 	if err != nil {
 		logger.L().Ctx(ctx).Warning("verify object, sending get object", helpers.Error(err), helpers.String("id", id.String()))
 		return c.callbacks.GetObject(ctx, id, baseObject)
@@ -373,12 +780,24 @@ func (c *Client) Batch(ctx context.Context, kind domain.Kind, batchType domain.B
 	return fmt.Errorf("batch type %s not supported", batchType)
 }
 
-func (c *Client) verifyObject(id domain.KindName, newChecksum string) ([]byte, error) {
+func (c *Client) WriteFailed(_ context.Context, _ domain.KindName, _ domain.WriteFailureReason, _ string) error {
+	return errors.New("write failed reports are only sent by the client")
+}
+
+func (c *Client) ResourceStatus(_ context.Context, _ domain.Kind, _ domain.ResourceState, _ string) error {
+	return errors.New("resource status reports are only sent by the client")
+}
+
+func (c *Client) PermissionReport(_ context.Context, _ domain.Kind, _ []string, _ string) error {
+	return errors.New("permission reports are only sent by the client")
+}
+
+func (c *Client) verifyObject(ctx context.Context, id domain.KindName, newChecksum string) ([]byte, error) {
 	obj, err := c.client.Resource(c.res).Namespace(id.Namespace).Get(context.Background(), id.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("get resource: %w", err)
 	}
-	object, err := utils.FilterAndMarshal(obj)
+	object, err := c.filterAndMarshal(ctx, obj)
 	if err != nil {
 		return nil, fmt.Errorf("marshal resource: %w", err)
 	}
@@ -392,48 +811,186 @@ func (c *Client) verifyObject(id domain.KindName, newChecksum string) ([]byte, e
 	return object, nil
 }
 
-func (c *Client) getExistingStorageObjects(ctx context.Context) (string, error) {
-	logger.L().Debug("getting existing objects from storage", helpers.String("resource", c.res.Resource))
-	list, err := c.client.Resource(c.res).Namespace("").List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		return "", fmt.Errorf("list resources: %w", err)
-	}
-	for _, d := range list.Items {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            d.GetName(),
-			Namespace:       d.GetNamespace(),
-			ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+func (c *Client) getExistingObjects(ctx context.Context) (string, error) {
+	logger.L().Debug("getting existing objects", helpers.String("resource", c.res.Resource))
+	// objects are verified page by page, so that the first ones are sent before the whole list is loaded
+	return c.listPages(ctx, func(items []unstructured.Unstructured) {
+		c.forEachListed(items, func(d *unstructured.Unstructured) {
+			id := domain.KindName{
+				Kind:            c.kind,
+				Name:            d.GetName(),
+				Namespace:       d.GetNamespace(),
+				ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+				UID:             string(d.GetUID()),
+			}
+			if isIgnored(d) {
+				c.ignored.Add(id.String())
+				return
+			}
+			newObject, err := c.getObjectFromUnstructured(ctx, d)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("cannot get object", helpers.Error(err), helpers.String("id", id.String()))
+				return
+			}
+			if err := c.callVerifyObject(ctx, id, newObject); err != nil {
+				logger.L().Ctx(ctx).Error("cannot handle added resource", helpers.Error(err), helpers.String("id", id.String()))
+			}
+		})
+	})
+}
+
+// forEachListed calls fn for each listed object, in list-then-get mode calls are spread
+// over a bounded number of workers as each of them fetches the full object.
+func (c *Client) forEachListed(items []unstructured.Unstructured, fn func(d *unstructured.Unstructured)) {
+	if !c.listThenGet || c.fetchConcurrency <= 1 {
+		for i := range items {
+			fn(&items[i])
 		}
-		obj, err := c.client.Resource(c.res).Namespace(d.GetNamespace()).Get(context.Background(), d.GetName(), metav1.GetOptions{})
-		if err != nil {
-			logger.L().Ctx(ctx).Error("cannot get object", helpers.Error(err), helpers.String("id", id.String()))
+		return
+	}
+	sem := make(chan struct{}, c.fetchConcurrency)
+	var wg sync.WaitGroup
+	for i := range items {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(d *unstructured.Unstructured) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(d)
+		}(&items[i])
+	}
+	wg.Wait()
+}
+
+// listPages lists all the objects of the resource page by page, calling process for each page.
+// It returns the resource version of the list, to start watching from. When the continue token
+// expires between two pages, the list is restarted from the first page, so process can see an
+// object more than once.
+func (c *Client) listPages(ctx context.Context, process func(items []unstructured.Unstructured)) (string, error) {
+	opts := metav1.ListOptions{Limit: c.pageSize}
+	restarts := 0
+	for {
+		list, err := c.client.Resource(c.res).Namespace("").List(context.Background(), opts)
+		if err != nil && opts.Continue != "" && isExpiredContinue(err) && restarts < maxListRestarts {
+			restarts++
+			logger.L().Ctx(ctx).Warning("list continue token expired, restarting list", helpers.Error(err),
+				helpers.String("resource", c.res.Resource), helpers.Int("restart", restarts))
+			opts.Continue = ""
 			continue
 		}
-		newObject, err := utils.FilterAndMarshal(obj)
 		if err != nil {
-			logger.L().Ctx(ctx).Error("cannot marshal object", helpers.Error(err), helpers.String("id", id.String()))
-			continue
+			return "", fmt.Errorf("list resources: %w", err)
 		}
-		err = c.callVerifyObject(ctx, id, newObject)
-		if err != nil {
-			logger.L().Ctx(ctx).Error("cannot handle added resource", helpers.Error(err), helpers.String("id", id.String()))
-			continue
+		process(list.Items)
+		opts.Continue = list.GetContinue()
+		if opts.Continue == "" {
+			return list.GetResourceVersion(), nil
+		}
+		logger.L().Debug("listing next page", helpers.String("resource", c.res.Resource), helpers.Int("items", len(list.Items)))
+		if ctx.Err() != nil {
+			return "", ctx.Err()
 		}
 	}
-	// set resource version to watch from
-	return list.GetResourceVersion(), nil
 }
 
-func (c *Client) getObjectFromUnstructured(d *unstructured.Unstructured) ([]byte, error) {
-	if c.res.Group == "spdx.softwarecomposition.kubescape.io" {
-		obj, err := c.client.Resource(c.res).Namespace(d.GetNamespace()).Get(context.Background(), d.GetName(), metav1.GetOptions{})
+// isExpiredContinue returns true if a paginated list failed because its continue token expired.
+func isExpiredContinue(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
+// verifyAll sends the checksums of all the objects of the resource.
+func (c *Client) verifyAll(ctx context.Context) error {
+	logger.L().Info("verifying all objects", helpers.String("resource", c.res.Resource))
+	_, err := c.listPages(ctx, func(items []unstructured.Unstructured) {
+		c.forEachListed(items, func(d *unstructured.Unstructured) {
+			if c.isSkippedChild(d) || isIgnored(d) {
+				return
+			}
+			id := domain.KindName{
+				Kind:            c.kind,
+				Name:            d.GetName(),
+				Namespace:       d.GetNamespace(),
+				ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+				UID:             string(d.GetUID()),
+			}
+			newObject, err := c.getObjectFromUnstructured(ctx, d)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("cannot get object", helpers.Error(err), helpers.String("id", id.String()))
+				return
+			}
+			if err := c.callVerifyObject(ctx, id, newObject); err != nil {
+				logger.L().Ctx(ctx).Error("cannot verify object", helpers.Error(err), helpers.String("id", id.String()))
+			}
+		})
+	})
+	return err
+}
+
+// sendReconciliationInventory sends the checksums of all the objects of the resource in a single reconciliation batch,
+// so that the backend knows the full inventory. Objects are listed page by page, only their checksums are kept.
+func (c *Client) sendReconciliationInventory(ctx context.Context) error {
+	ctx = utils.ContextFromGeneric(ctx, domain.Generic{})
+	event := domain.EventNewChecksum
+	items := domain.BatchItems{NewChecksum: []domain.NewChecksum{}}
+	listed := mapset.NewSet[string]()
+	var mu sync.Mutex
+	if _, err := c.listPages(ctx, func(page []unstructured.Unstructured) {
+		c.forEachListed(page, func(d *unstructured.Unstructured) {
+			if c.isSkippedChild(d) || isIgnored(d) {
+				return
+			}
+			if !listed.Add(fmt.Sprintf("%s/%s", d.GetNamespace(), d.GetName())) {
+				// listed again after the list was restarted
+				return
+			}
+			object, err := c.getObjectFromUnstructured(ctx, d)
+			if err != nil {
+				logger.L().Ctx(ctx).Warning("cannot get object for reconciliation", helpers.Error(err),
+					helpers.String("resource", c.res.Resource), helpers.String("name", d.GetName()))
+				return
+			}
+			checksum, err := utils.CanonicalHash(object)
+			if err != nil {
+				logger.L().Ctx(ctx).Warning("cannot calculate checksum for reconciliation", helpers.Error(err),
+					helpers.String("resource", c.res.Resource), helpers.String("name", d.GetName()))
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			items.NewChecksum = append(items.NewChecksum, domain.NewChecksum{
+				Checksum:        checksum,
+				Event:           &event,
+				Kind:            c.kind,
+				Name:            d.GetName(),
+				Namespace:       d.GetNamespace(),
+				ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+				UID:             string(d.GetUID()),
+			})
+		})
+	}); err != nil {
+		// a partial inventory would make the backend delete the objects that were not listed
+		return err
+	}
+	logger.L().Ctx(ctx).Debug("sending reconciliation inventory", helpers.String("resource", c.res.Resource), helpers.Int("objects", len(items.NewChecksum)))
+	return c.callbacks.Batch(ctx, *c.kind, domain.ReconciliationBatch, items)
+}
+
+// getObjectFromUnstructured marshals a listed or watched object, fetching it first in list-then-get mode.
+func (c *Client) getObjectFromUnstructured(ctx context.Context, d *unstructured.Unstructured) ([]byte, error) {
+	if c.listThenGet {
+		if err := c.fetchLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("wait for fetch rate limiter: %w", err)
+		}
+		// stopping the client cancels in-flight fetches
+		obj, err := c.client.Resource(c.res).Namespace(d.GetNamespace()).Get(ctx, d.GetName(), metav1.GetOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("get resource: %w", err)
 		}
-		return utils.FilterAndMarshal(obj)
+		return c.filterAndMarshal(ctx, obj)
 	}
-	return utils.FilterAndMarshal(d)
+	return c.filterAndMarshal(ctx, d)
 }
 
 // Batch processing functions
@@ -443,37 +1000,48 @@ func reconcileBatchProcessingFunc(ctx context.Context, c *Client, items domain.B
 		return fmt.Errorf("reconciliation batch (%s) was empty - expected at least one NewChecksum message", c.res.Resource)
 	}
 
-	// create a map of resources from the client
-	list, err := c.client.Resource(c.res).Namespace("").List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("list resources: %w", err)
-	}
-	clientItems := map[string]unstructured.Unstructured{}
-	clientItemsSet := mapset.NewSet[string]()
-	for _, item := range list.Items {
-		k := fmt.Sprintf("%s/%s", item.GetNamespace(), item.GetName())
-		clientItems[k] = item
-		clientItemsSet.Add(k)
-	}
-
 	// create a map of resources from the server
 	serverItems := map[string]domain.NewChecksum{}
-	serverItemsSet := mapset.NewSet[string]()
 	for _, item := range items.NewChecksum {
-		k := fmt.Sprintf("%s/%s", item.Namespace, item.Name)
-		serverItems[k] = item
-		serverItemsSet.Add(k)
+		serverItems[fmt.Sprintf("%s/%s", item.Namespace, item.Name)] = item
 	}
 
-	// resources that should not be in server, send delete
-	for _, k := range serverItemsSet.Difference(clientItemsSet).ToSlice() {
-		item := serverItems[k]
+	// resources from the client are compared page by page, only their keys are kept
+	var err error
+	clientItemsSet := mapset.NewThreadUnsafeSet[string]()
+	if _, listErr := c.listPages(ctx, func(page []unstructured.Unstructured) {
+		for i := range page {
+			resource := &page[i]
+			if c.isSkippedChild(resource) || isIgnored(resource) {
+				continue
+			}
+			k := fmt.Sprintf("%s/%s", resource.GetNamespace(), resource.GetName())
+			if !clientItemsSet.Add(k) {
+				// listed again after the list was restarted
+				continue
+			}
+			if item, ok := serverItems[k]; ok {
+				err = multierr.Append(err, c.reconcileCommon(ctx, item, resource))
+			} else {
+				err = multierr.Append(err, c.reconcileMissingInServer(ctx, resource))
+			}
+		}
+	}); listErr != nil {
+		// without the full list, we cannot tell which resources should not be in server
+		return multierr.Append(err, listErr)
+	}
 
+	// resources that should not be in server, send delete
+	for k, item := range serverItems {
+		if clientItemsSet.Contains(k) {
+			continue
+		}
 		id := domain.KindName{
 			Kind:            c.kind,
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 
 		logger.L().Debug("resource should not be in server, sending delete message",
@@ -481,135 +1049,100 @@ func reconcileBatchProcessingFunc(ctx context.Context, c *Client, items domain.B
 			helpers.String("name", item.Name),
 			helpers.String("namespace", item.Namespace))
 		err = multierr.Append(err, c.callbacks.DeleteObject(ctx, id))
+		// remove from known resources
+		c.ShadowObjects.Delete(id.String())
 	}
 
-	// resources in common, check resource version
-	for _, k := range serverItemsSet.Intersect(clientItemsSet).ToSlice() {
-		item := serverItems[k]
-		resource := clientItems[k]
-		currentVersion := domain.ToResourceVersion(resource.GetResourceVersion())
-		if currentVersion == item.ResourceVersion {
-			// resource has same version, skipping
-			logger.L().Debug("resource has same version, skipping",
-				helpers.String("resource", item.Kind.String()),
-				helpers.String("name", item.Name),
-				helpers.String("namespace", item.Namespace),
-				helpers.Int("resource version", currentVersion))
-			continue
-		}
+	return err
+}
 
-		// resource has changed, sending a put message
-		logger.L().Debug("resource has changed, sending put message",
-			helpers.String("resource", item.Kind.String()),
-			helpers.String("name", item.Name),
-			helpers.String("namespace", item.Namespace),
-			helpers.Int("batch resource version", item.ResourceVersion),
-			helpers.Int("current resource version", currentVersion))
-		newObject, marshalErr := c.getObjectFromUnstructured(&resource)
-		if marshalErr != nil {
-			err = multierr.Append(err, fmt.Errorf("marshal resource: %w", marshalErr))
-			continue
-		}
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
-		}
-		err = multierr.Append(err, c.callbacks.PutObject(ctx, id, newObject))
+// reconcileCommon sends a resource in common with the server if it differs.
+func (c *Client) reconcileCommon(ctx context.Context, item domain.NewChecksum, resource *unstructured.Unstructured) error {
+	newObject, changed, err := c.differsFromServer(ctx, item, resource)
+	if err != nil || !changed {
+		return err
 	}
+	id := domain.KindName{
+		Kind:            c.kind,
+		Name:            item.Name,
+		Namespace:       item.Namespace,
+		ResourceVersion: domain.ToResourceVersion(resource.GetResourceVersion()),
+		UID:             string(resource.GetUID()),
+	}
+	return c.callbacks.PutObject(ctx, id, newObject)
+}
 
-	// resources missing in server, send verify checksum
-	for _, k := range clientItemsSet.Difference(serverItemsSet).ToSlice() {
-		item := clientItems[k]
-
-		resourceVersion := domain.ToResourceVersion(item.GetResourceVersion())
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.GetName(),
-			Namespace:       item.GetNamespace(),
-			ResourceVersion: resourceVersion,
-		}
-
-		newObject, marshalErr := utils.FilterAndMarshal(&item)
-		if marshalErr != nil {
-			err = multierr.Append(err, fmt.Errorf("marshal resource: %w", marshalErr))
-			continue
-		}
-
-		logger.L().Debug("resource missing in server, sending verify message",
-			helpers.String("resource", c.res.Resource),
-			helpers.String("name", item.GetName()),
-			helpers.String("namespace", item.GetNamespace()))
-		// remove cached object
-		delete(c.ShadowObjects, id.String())
-		// send verify message
-		err = multierr.Append(err, c.callVerifyObject(ctx, id, newObject))
+// reconcileMissingInServer sends verify checksum for a resource missing in server, or prunes it if it was created from the backend.
+func (c *Client) reconcileMissingInServer(ctx context.Context, item *unstructured.Unstructured) error {
+	if c.canPrune(item) {
+		return c.pruneObject(ctx, item)
 	}
 
-	return nil
-}
+	resourceVersion := domain.ToResourceVersion(item.GetResourceVersion())
+	id := domain.KindName{
+		Kind:            c.kind,
+		Name:            item.GetName(),
+		Namespace:       item.GetNamespace(),
+		ResourceVersion: resourceVersion,
+		UID:             string(item.GetUID()),
+	}
 
-func findResourceInList(list []unstructured.Unstructured, namespace, name string) int {
-	for i, resource := range list {
-		if resource.GetName() == name && resource.GetNamespace() == namespace {
-			return i
-		}
+	// listed objects are partial in list-then-get mode, send the same payload as the watch
+	newObject, err := c.getObjectFromUnstructured(ctx, item)
+	if err != nil {
+		return fmt.Errorf("marshal resource: %w", err)
 	}
 
-	return -1
+	logger.L().Debug("resource missing in server, sending verify message",
+		helpers.String("resource", c.res.Resource),
+		helpers.String("name", item.GetName()),
+		helpers.String("namespace", item.GetNamespace()))
+	// remove cached object
+	c.ShadowObjects.Delete(id.String())
+	// send verify message
+	return c.callVerifyObject(ctx, id, newObject)
 }
 
-func defaultBatchProcessingFunc(ctx context.Context, c *Client, items domain.BatchItems) error {
-	var err error
-	for _, item := range items.GetObject {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
-		}
-		err = multierr.Append(err, c.GetObject(ctx, id, []byte(item.BaseObject)))
+// differsFromServer compares an object with the one known by the server according to the reconciliation mode.
+// When they differ, the marshalled object is returned so that it can be sent.
+func (c *Client) differsFromServer(ctx context.Context, item domain.NewChecksum, resource *unstructured.Unstructured) ([]byte, bool, error) {
+	currentVersion := domain.ToResourceVersion(resource.GetResourceVersion())
+	sameVersion := currentVersion == item.ResourceVersion
+	logFields := []helpers.IDetails{
+		helpers.String("resource", c.res.Resource),
+		helpers.String("name", item.Name),
+		helpers.String("namespace", item.Namespace),
+		helpers.Int("batch resource version", item.ResourceVersion),
+		helpers.Int("current resource version", currentVersion),
 	}
-
-	for _, item := range items.NewChecksum {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
+	switch c.reconciliationMode {
+	case domain.ReconcileByResourceVersion:
+		if sameVersion {
+			logger.L().Debug("resource has same version, skipping", logFields...)
+			return nil, false, nil
 		}
-		err = multierr.Append(err, c.VerifyObject(ctx, id, item.Checksum))
-	}
-
-	for _, item := range items.ObjectDeleted {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
+	case domain.ReconcileByChecksum:
+	default:
+		// the server often has no resource version, only trust it when set
+		if sameVersion && item.ResourceVersion != 0 {
+			logger.L().Debug("resource has same version, skipping", logFields...)
+			return nil, false, nil
 		}
-		err = multierr.Append(err, c.DeleteObject(ctx, id))
 	}
-
-	for _, item := range items.PatchObject {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
-		}
-		err = multierr.Append(err, c.PatchObject(ctx, id, item.Checksum, []byte(item.Patch)))
+	newObject, err := c.getObjectFromUnstructured(ctx, resource)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal resource: %w", err)
 	}
-
-	for _, item := range items.PutObject {
-		id := domain.KindName{
-			Kind:            c.kind,
-			Name:            item.Name,
-			Namespace:       item.Namespace,
-			ResourceVersion: item.ResourceVersion,
+	if c.reconciliationMode != domain.ReconcileByResourceVersion && item.Checksum != "" {
+		checksum, err := utils.CanonicalHash(newObject)
+		if err != nil {
+			return nil, false, fmt.Errorf("calculate checksum: %w", err)
+		}
+		if checksum == item.Checksum {
+			logger.L().Debug("resource has same checksum, skipping", logFields...)
+			return nil, false, nil
 		}
-		err = multierr.Append(err, c.PutObject(ctx, id, []byte(item.Object)))
 	}
-	return err
+	logger.L().Debug("resource has changed, sending put message", logFields...)
+	return newObject, true, nil
 }
@@ -2,22 +2,34 @@ package incluster
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/kubescape/synchronizer/config"
 	"github.com/kubescape/synchronizer/domain"
 	"github.com/kubescape/synchronizer/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/k3s"
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/utils/ptr"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -55,7 +67,7 @@ func TestClient_watchRetry(t *testing.T) {
 		kind          *domain.Kind
 		callbacks     domain.Callbacks
 		res           schema.GroupVersionResource
-		ShadowObjects map[string][]byte
+		ShadowObjects ShadowStore
 		Strategy      domain.Strategy
 	}
 	type args struct {
@@ -106,6 +118,7 @@ func TestClient_watchRetry(t *testing.T) {
 				res:           tt.fields.res,
 				ShadowObjects: tt.fields.ShadowObjects,
 				Strategy:      tt.fields.Strategy,
+				health:        newResourceHealth(),
 			}
 			go c.watchRetry(ctx, tt.args.watchOpts, tt.args.eventQueue)
 			time.Sleep(5 * time.Second)
@@ -123,3 +136,991 @@ func TestClient_watchRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_checkWritable(t *testing.T) {
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+	}
+	live := func(ignored bool) *unstructured.Unstructured {
+		d := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "test",
+				"namespace": "default",
+			},
+		}}
+		if ignored {
+			d.SetAnnotations(map[string]string{IgnoreKey: "true"})
+		}
+		return d
+	}
+	tests := []struct {
+		name        string
+		direction   domain.Direction
+		live        *unstructured.Unstructured
+		wantErr     bool
+		wantReports []domain.WriteFailureReason
+	}{
+		{
+			name:        "default direction is read-only",
+			wantErr:     true,
+			wantReports: []domain.WriteFailureReason{domain.WriteRefused},
+		},
+		{
+			name:        "cluster to backend",
+			direction:   domain.ClusterToBackend,
+			wantErr:     true,
+			wantReports: []domain.WriteFailureReason{domain.WriteRefused},
+		},
+		{
+			name:        "backend to cluster",
+			direction:   domain.BackendToCluster,
+			wantReports: []domain.WriteFailureReason{},
+		},
+		{
+			name:        "bidirectional",
+			direction:   domain.Bidirectional,
+			wantReports: []domain.WriteFailureReason{},
+		},
+		{
+			name:        "existing object",
+			direction:   domain.Bidirectional,
+			live:        live(false),
+			wantReports: []domain.WriteFailureReason{},
+		},
+		{
+			name:        "ignored object",
+			direction:   domain.Bidirectional,
+			live:        live(true),
+			wantErr:     true,
+			wantReports: []domain.WriteFailureReason{domain.WriteRefused},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := []domain.WriteFailureReason{}
+			c := &Client{
+				kind:      id.Kind,
+				res:       schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				direction: tt.direction,
+				callbacks: domain.Callbacks{
+					WriteFailed: func(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+						reports = append(reports, reason)
+						return nil
+					},
+				},
+			}
+			err := c.checkWritable(context.TODO(), id, tt.live)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantReports, reports)
+		})
+	}
+}
+
+func TestClient_PutObject_syncStatus(t *testing.T) {
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+	}
+	object := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"default"},"spec":{"replicas":1},"status":{"replicas":1}}`)
+	tests := []struct {
+		name            string
+		syncStatus      bool
+		dryRun          bool
+		wantSubresource []string
+	}{
+		{
+			name:            "status not synced",
+			wantSubresource: []string{""},
+		},
+		{
+			name:            "status synced after main apply",
+			syncStatus:      true,
+			wantSubresource: []string{"", "status"},
+		},
+		{
+			name:            "status not applied in dry-run",
+			syncStatus:      true,
+			dryRun:          true,
+			wantSubresource: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			var subresources []string
+			client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patch := action.(k8stesting.PatchAction)
+				assert.Equal(t, types.ApplyPatchType, patch.GetPatchType())
+				if patch.GetSubresource() == "status" {
+					// only the status is sent to the subresource
+					assert.JSONEq(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"default"},"status":{"replicas":1}}`, string(patch.GetPatch()))
+				}
+				subresources = append(subresources, patch.GetSubresource())
+				var obj unstructured.Unstructured
+				require.NoError(t, obj.UnmarshalJSON(object))
+				return true, &obj, nil
+			})
+			c := &Client{
+				client:       client,
+				kind:         id.Kind,
+				res:          schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				direction:    domain.BackendToCluster,
+				fieldManager: config.DefaultFieldManager,
+				dryRun:       tt.dryRun,
+				syncStatus:   tt.syncStatus,
+			}
+			err := c.PutObject(context.TODO(), id, object)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSubresource, subresources)
+		})
+	}
+}
+
+func TestClient_sendReconciliationInventory(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	newDeployment := func(name, resourceVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "default",
+				"resourceVersion": resourceVersion,
+			},
+		}}
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{res: "DeploymentList"},
+		newDeployment("a", "1"), newDeployment("b", "2"))
+	var gotBatchType domain.BatchType
+	var gotItems domain.BatchItems
+	c := &Client{
+		client:    client,
+		kind:      &domain.Kind{Group: res.Group, Version: res.Version, Resource: res.Resource},
+		res:       res,
+		ownership: config.DefaultOwnershipRules,
+		callbacks: domain.Callbacks{
+			Batch: func(_ context.Context, _ domain.Kind, batchType domain.BatchType, items domain.BatchItems) error {
+				gotBatchType = batchType
+				gotItems = items
+				return nil
+			},
+		},
+	}
+	err := c.sendReconciliationInventory(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ReconciliationBatch, gotBatchType)
+	require.Len(t, gotItems.NewChecksum, 2)
+	names := map[string]int{}
+	for _, item := range gotItems.NewChecksum {
+		names[item.Name] = item.ResourceVersion
+		assert.NotEmpty(t, item.Checksum)
+		assert.Equal(t, "default", item.Namespace)
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, names)
+}
+
+func TestClient_differsFromServer(t *testing.T) {
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "test",
+			"namespace":       "default",
+			"resourceVersion": "2",
+		},
+	}}
+	c := &Client{res: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}}
+	object, err := c.filterAndMarshal(context.TODO(), resource)
+	require.NoError(t, err)
+	checksum, err := utils.CanonicalHash(object)
+	require.NoError(t, err)
+	tests := []struct {
+		name            string
+		mode            domain.ReconciliationMode
+		resourceVersion int
+		checksum        string
+		want            bool
+	}{
+		{name: "resourceVersion: same version", mode: domain.ReconcileByResourceVersion, resourceVersion: 2, checksum: "other"},
+		{name: "resourceVersion: other version", mode: domain.ReconcileByResourceVersion, resourceVersion: 1, checksum: checksum, want: true},
+		{name: "checksum: same checksum", mode: domain.ReconcileByChecksum, resourceVersion: 1, checksum: checksum},
+		{name: "checksum: other checksum", mode: domain.ReconcileByChecksum, resourceVersion: 2, checksum: "other", want: true},
+		{name: "checksum: missing checksum", mode: domain.ReconcileByChecksum, resourceVersion: 2, want: true},
+		{name: "hybrid: same version", resourceVersion: 2, checksum: "other"},
+		{name: "hybrid: unknown version, same checksum", resourceVersion: 0, checksum: checksum},
+		{name: "hybrid: unknown version, other checksum", resourceVersion: 0, checksum: "other", want: true},
+		{name: "hybrid: other version, same checksum", mode: domain.ReconcileHybrid, resourceVersion: 1, checksum: checksum},
+		{name: "hybrid: other version, missing checksum", mode: domain.ReconcileHybrid, resourceVersion: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.reconciliationMode = tt.mode
+			item := domain.NewChecksum{Name: "test", Namespace: "default", ResourceVersion: tt.resourceVersion, Checksum: tt.checksum}
+			newObject, changed, err := c.differsFromServer(context.TODO(), item, resource)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, changed)
+			if tt.want {
+				assert.Equal(t, object, newObject)
+			}
+		})
+	}
+}
+
+// pagingLister returns two pages and records the list options
+type pagingLister struct {
+	dynamic.NamespaceableResourceInterface
+	opts    []metav1.ListOptions
+	expired int // number of times the continue token is expired
+}
+
+func (l *pagingLister) Namespace(string) dynamic.ResourceInterface {
+	return l
+}
+
+func (l *pagingLister) List(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	l.opts = append(l.opts, opts)
+	if opts.Continue != "" && l.expired > 0 {
+		l.expired--
+		return nil, apierrors.NewResourceExpired("continue token expired")
+	}
+	list := &unstructured.UnstructuredList{}
+	item := unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment"}}
+	if opts.Continue == "" {
+		item.SetName("a")
+		list.SetContinue("page2")
+	} else {
+		item.SetName("b")
+		list.SetResourceVersion("42")
+	}
+	list.Items = []unstructured.Unstructured{item}
+	return list, nil
+}
+
+type pagingClient struct {
+	dynamic.Interface
+	lister *pagingLister
+}
+
+func (p pagingClient) Resource(schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return p.lister
+}
+
+func TestClient_listPages(t *testing.T) {
+	lister := &pagingLister{}
+	c := &Client{
+		client:   pagingClient{lister: lister},
+		res:      schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		pageSize: 1,
+	}
+	var names []string
+	resourceVersion, err := c.listPages(context.TODO(), func(items []unstructured.Unstructured) {
+		for _, item := range items {
+			names = append(names, item.GetName())
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "42", resourceVersion)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Equal(t, []metav1.ListOptions{{Limit: 1}, {Limit: 1, Continue: "page2"}}, lister.opts)
+}
+
+func TestClient_listPages_expiredContinue(t *testing.T) {
+	tests := []struct {
+		name      string
+		expired   int
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "restarted",
+			expired:   1,
+			wantNames: []string{"a", "a", "b"},
+		},
+		{
+			name:      "too many restarts",
+			expired:   maxListRestarts + 1,
+			wantNames: []string{"a", "a", "a", "a"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				client:   pagingClient{lister: &pagingLister{expired: tt.expired}},
+				res:      schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				pageSize: 1,
+			}
+			var names []string
+			_, err := c.listPages(context.TODO(), func(items []unstructured.Unstructured) {
+				for _, item := range items {
+					names = append(names, item.GetName())
+				}
+			})
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func Test_reconcileBatchProcessingFunc(t *testing.T) {
+	var verified, put, deleted []string
+	c := &Client{
+		// the first list is restarted, objects listed twice are only reconciled once
+		client:        pagingClient{lister: &pagingLister{expired: 1}},
+		kind:          &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		res:           schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		pageSize:      1,
+		ownership:     config.DefaultOwnershipRules,
+		ShadowObjects: NewMemoryShadowStore(),
+		callbacks: domain.Callbacks{
+			VerifyObject: func(_ context.Context, id domain.KindName, _ string) error {
+				verified = append(verified, id.Name)
+				return nil
+			},
+			PutObject: func(_ context.Context, id domain.KindName, _ []byte) error {
+				put = append(put, id.Name)
+				return nil
+			},
+			DeleteObject: func(_ context.Context, id domain.KindName) error {
+				deleted = append(deleted, id.Name)
+				return nil
+			},
+		},
+	}
+	deletedId := domain.KindName{Kind: c.kind, Name: "c"}
+	c.ShadowObjects.Set(deletedId.String(), []byte(`{"kind":"Deployment"}`))
+	event := domain.EventNewChecksum
+	items := domain.BatchItems{NewChecksum: []domain.NewChecksum{
+		{Name: "b", Checksum: "outdated", Event: &event, Kind: c.kind},
+		{Name: "c", Checksum: "deleted", Event: &event, Kind: c.kind},
+	}}
+	err := reconcileBatchProcessingFunc(context.TODO(), c, items)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, verified)
+	assert.Equal(t, []string{"b"}, put)
+	assert.Equal(t, []string{"c"}, deleted)
+	// the deleted object is not kept as a base for patches
+	_, ok := c.ShadowObjects.Get(deletedId.String())
+	assert.False(t, ok)
+}
+
+func TestClient_sendReconciliationInventory_pages(t *testing.T) {
+	tests := []struct {
+		name      string
+		expired   int
+		want      [][]string
+		wantError bool
+	}{
+		{
+			name: "all pages in one batch",
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name:    "objects listed again after a restart are sent once",
+			expired: 1,
+			want:    [][]string{{"a", "b"}},
+		},
+		{
+			name:      "partial inventory is not sent",
+			expired:   maxListRestarts + 1,
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches [][]string
+			c := &Client{
+				client:    pagingClient{lister: &pagingLister{expired: tt.expired}},
+				kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+				res:       schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				pageSize:  1,
+				ownership: config.DefaultOwnershipRules,
+				callbacks: domain.Callbacks{
+					Batch: func(_ context.Context, _ domain.Kind, _ domain.BatchType, items domain.BatchItems) error {
+						var names []string
+						for _, item := range items.NewChecksum {
+							names = append(names, item.Name)
+						}
+						batches = append(batches, names)
+						return nil
+					},
+				},
+			}
+			err := c.sendReconciliationInventory(context.TODO())
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, batches)
+		})
+	}
+}
+
+func TestClient_getObjectFromUnstructured(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "spdx.softwarecomposition.kubescape.io", Version: "v1beta1", Resource: "applicationprofiles"}
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "spdx.softwarecomposition.kubescape.io/v1beta1",
+		"kind":       "ApplicationProfile",
+		"metadata": map[string]interface{}{
+			"name":      "test",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{"architectures": []interface{}{"amd64"}},
+	}}
+	listed := stored.DeepCopy()
+	unstructured.RemoveNestedField(listed.Object, "spec")
+	tests := []struct {
+		name        string
+		listThenGet bool
+		wantSpec    bool
+	}{
+		{
+			name:        "listed object is sent as is",
+			listThenGet: false,
+			wantSpec:    false,
+		},
+		{
+			name:        "list-then-get fetches the full object",
+			listThenGet: true,
+			wantSpec:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				client:       fake.NewSimpleDynamicClient(runtime.NewScheme(), stored.DeepCopy()),
+				res:          res,
+				listThenGet:  tt.listThenGet,
+				fetchLimiter: rate.NewLimiter(rate.Inf, 1),
+			}
+			object, err := c.getObjectFromUnstructured(context.TODO(), listed)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSpec, strings.Contains(string(object), `"spec"`))
+		})
+	}
+}
+
+func TestClient_reconcileMissingInServer(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "spdx.softwarecomposition.kubescape.io", Version: "v1beta1", Resource: "applicationprofiles"}
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "spdx.softwarecomposition.kubescape.io/v1beta1",
+		"kind":       "ApplicationProfile",
+		"metadata": map[string]interface{}{
+			"name":      "test",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{"architectures": []interface{}{"amd64"}},
+	}}
+	listed := stored.DeepCopy()
+	unstructured.RemoveNestedField(listed.Object, "spec")
+	tests := []struct {
+		name        string
+		listThenGet bool
+		want        *unstructured.Unstructured
+	}{
+		{
+			name:        "listed object is verified",
+			listThenGet: false,
+			want:        listed,
+		},
+		{
+			name:        "list-then-get verifies the full object",
+			listThenGet: true,
+			want:        stored,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checksum string
+			c := &Client{
+				client:        fake.NewSimpleDynamicClient(runtime.NewScheme(), stored.DeepCopy()),
+				kind:          &domain.Kind{Group: res.Group, Version: res.Version, Resource: res.Resource},
+				res:           res,
+				listThenGet:   tt.listThenGet,
+				fetchLimiter:  rate.NewLimiter(rate.Inf, 1),
+				ShadowObjects: NewMemoryShadowStore(),
+				callbacks: domain.Callbacks{
+					VerifyObject: func(_ context.Context, _ domain.KindName, sum string) error {
+						checksum = sum
+						return nil
+					},
+				},
+			}
+			require.NoError(t, c.reconcileMissingInServer(context.TODO(), listed.DeepCopy()))
+			want, err := c.filterAndMarshal(context.TODO(), tt.want.DeepCopy())
+			require.NoError(t, err)
+			wantChecksum, err := utils.CanonicalHash(want)
+			require.NoError(t, err)
+			assert.Equal(t, wantChecksum, checksum)
+		})
+	}
+}
+
+func TestClient_forEachListed(t *testing.T) {
+	items := make([]unstructured.Unstructured, 20)
+	for i := range items {
+		items[i].SetName(fmt.Sprintf("item-%d", i))
+	}
+	c := &Client{listThenGet: true, fetchConcurrency: 3}
+	var running, maxRunning, calls atomic.Int32
+	c.forEachListed(items, func(d *unstructured.Unstructured) {
+		current := running.Add(1)
+		for {
+			seen := maxRunning.Load()
+			if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		calls.Add(1)
+	})
+	assert.Equal(t, int32(len(items)), calls.Load())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+}
+
+func TestClient_checkBaseVersion(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "test",
+			"namespace":       "default",
+			"resourceVersion": "5",
+			"uid":             "1234",
+		},
+	}}
+	tests := []struct {
+		name            string
+		resourceVersion int
+		uid             string
+		objects         []runtime.Object
+		wantErr         bool
+	}{
+		{
+			name:    "no base version",
+			objects: []runtime.Object{live},
+		},
+		{
+			name:            "object does not exist",
+			resourceVersion: 3,
+			uid:             "1234",
+		},
+		{
+			name:            "same version",
+			resourceVersion: 5,
+			uid:             "1234",
+			objects:         []runtime.Object{live},
+		},
+		{
+			name:            "live object is newer",
+			resourceVersion: 3,
+			objects:         []runtime.Object{live},
+			wantErr:         true,
+		},
+		{
+			name:    "object was recreated",
+			uid:     "5678",
+			objects: []runtime.Object{live},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []domain.WriteFailureReason
+			c := &Client{
+				client: fake.NewSimpleDynamicClient(runtime.NewScheme(), tt.objects...),
+				res:    res,
+				callbacks: domain.Callbacks{
+					WriteFailed: func(_ context.Context, _ domain.KindName, reason domain.WriteFailureReason, _ string) error {
+						reports = append(reports, reason)
+						return nil
+					},
+				},
+			}
+			id := domain.KindName{
+				Kind:            &domain.Kind{Group: res.Group, Version: res.Version, Resource: res.Resource},
+				Name:            "test",
+				Namespace:       "default",
+				ResourceVersion: tt.resourceVersion,
+				UID:             tt.uid,
+			}
+			// nil when the object does not exist
+			live, _ := c.client.Resource(res).Namespace("default").Get(context.TODO(), "test", metav1.GetOptions{})
+			err := c.checkBaseVersion(context.TODO(), id, live)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errBaseVersionOutdated)
+				assert.Equal(t, []domain.WriteFailureReason{domain.WriteConflict}, reports)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, reports)
+		})
+	}
+}
+
+func Test_deletePreconditions(t *testing.T) {
+	tests := []struct {
+		name string
+		id   domain.KindName
+		want *metav1.Preconditions
+	}{
+		{
+			name: "no preconditions",
+			id:   domain.KindName{Name: "test"},
+		},
+		{
+			name: "uid only",
+			id:   domain.KindName{Name: "test", UID: "1234"},
+			want: &metav1.Preconditions{UID: ptr.To(types.UID("1234"))},
+		},
+		{
+			name: "uid and resource version",
+			id:   domain.KindName{Name: "test", UID: "1234", ResourceVersion: 5},
+			want: &metav1.Preconditions{UID: ptr.To(types.UID("1234")), ResourceVersion: ptr.To("5")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, deletePreconditions(tt.id))
+		})
+	}
+}
+
+// recordedOptions are the options of the writes, the fake dynamic client drops them
+type recordedOptions struct {
+	applies []metav1.ApplyOptions
+	deletes []metav1.DeleteOptions
+}
+
+// recordingClient records the options of the writes before passing them to the fake dynamic client
+type recordingClient struct {
+	dynamic.Interface
+	recorded *recordedOptions
+}
+
+func (c recordingClient) Resource(res schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return recordingResource{NamespaceableResourceInterface: c.Interface.Resource(res), recorded: c.recorded}
+}
+
+type recordingResource struct {
+	dynamic.NamespaceableResourceInterface
+	recorded *recordedOptions
+}
+
+func (r recordingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return recordingNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), recorded: r.recorded}
+}
+
+type recordingNamespacedResource struct {
+	dynamic.ResourceInterface
+	recorded *recordedOptions
+}
+
+func (r recordingNamespacedResource) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, opts metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.recorded.applies = append(r.recorded.applies, opts)
+	return r.ResourceInterface.Apply(ctx, name, obj, opts, subresources...)
+}
+
+func (r recordingNamespacedResource) Delete(ctx context.Context, name string, opts metav1.DeleteOptions, subresources ...string) error {
+	r.recorded.deletes = append(r.recorded.deletes, opts)
+	return r.ResourceInterface.Delete(ctx, name, opts, subresources...)
+}
+
+func TestClient_PutObject_conflictPolicy(t *testing.T) {
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+		UID:       "1234",
+	}
+	object := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"default"},"spec":{"replicas":1}}`)
+	conflict := apierrors.NewConflict(gr, "test", errors.New("conflict with another field manager"))
+	immutable := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "test",
+		field.ErrorList{field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable")})
+	tests := []struct {
+		name                string
+		conflictPolicy      domain.ConflictPolicy
+		recreateOnImmutable bool
+		applyErr            error // returned by the first apply
+		wantErr             bool
+		wantReason          domain.WriteFailureReason
+		wantForce           []bool // of each apply
+		wantDeletes         []metav1.DeleteOptions
+	}{
+		{
+			name:       "conflict reported",
+			applyErr:   conflict,
+			wantErr:    true,
+			wantReason: domain.WriteConflict,
+			wantForce:  []bool{false},
+		},
+		{
+			name:           "conflict skipped",
+			conflictPolicy: domain.ConflictSkip,
+			applyErr:       conflict,
+			wantForce:      []bool{false},
+		},
+		{
+			name:           "conflict forced",
+			conflictPolicy: domain.ConflictForce,
+			wantForce:      []bool{true},
+		},
+		{
+			name:      "immutable field not recreated",
+			applyErr:  immutable,
+			wantErr:   true,
+			wantForce: []bool{false},
+		},
+		{
+			name:                "immutable field recreated with preconditions",
+			recreateOnImmutable: true,
+			applyErr:            immutable,
+			wantForce:           []bool{false, true},
+			wantDeletes:         []metav1.DeleteOptions{{Preconditions: &metav1.Preconditions{UID: ptr.To(types.UID("1234"))}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			var applies int
+			client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				applies++
+				if applies == 1 && tt.applyErr != nil {
+					return true, nil, tt.applyErr
+				}
+				var obj unstructured.Unstructured
+				require.NoError(t, obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()))
+				return true, &obj, nil
+			})
+			client.PrependReactor("delete", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+			recorded := &recordedOptions{}
+			var reason domain.WriteFailureReason
+			c := &Client{
+				client:              recordingClient{Interface: client, recorded: recorded},
+				kind:                id.Kind,
+				res:                 schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				direction:           domain.BackendToCluster,
+				fieldManager:        config.DefaultFieldManager,
+				conflictPolicy:      tt.conflictPolicy,
+				recreateOnImmutable: tt.recreateOnImmutable,
+				callbacks: domain.Callbacks{
+					WriteFailed: func(_ context.Context, _ domain.KindName, r domain.WriteFailureReason, _ string) error {
+						reason = r
+						return nil
+					},
+				},
+			}
+			err := c.putObject(context.TODO(), id, object, operationPut)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantReason, reason)
+			var force []bool
+			for _, opts := range recorded.applies {
+				force = append(force, opts.Force)
+			}
+			assert.Equal(t, tt.wantForce, force)
+			assert.Equal(t, tt.wantDeletes, recorded.deletes)
+		})
+	}
+}
+
+func TestClient_dryRun(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+	}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "test",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{"replicas": int64(1)},
+	}}
+	patch := []byte(`{"spec":{"replicas":2}}`)
+	tests := []struct {
+		name        string
+		write       func(ctx context.Context, c *Client) error
+		wantApplies int
+	}{
+		{
+			name:        "put",
+			wantApplies: 1,
+			write: func(ctx context.Context, c *Client) error {
+				return c.PutObject(ctx, id, []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"default"},"spec":{"replicas":2}}`))
+			},
+		},
+		{
+			name:        "patch",
+			wantApplies: 1,
+			write: func(ctx context.Context, c *Client) error {
+				object, err := c.filterAndMarshal(ctx, live.DeepCopy())
+				require.NoError(t, err)
+				modified, err := utils.ApplyPatch(domain.MergePatch, object, patch)
+				require.NoError(t, err)
+				checksum, err := utils.CanonicalHash(modified)
+				require.NoError(t, err)
+				return c.PatchObject(ctx, id, checksum, domain.MergePatch, patch)
+			},
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, c *Client) error {
+				return c.DeleteObject(ctx, id)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme(), live.DeepCopy())
+			client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				var obj unstructured.Unstructured
+				require.NoError(t, obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()))
+				return true, &obj, nil
+			})
+			recorded := &recordedOptions{}
+			c := &Client{
+				client:        recordingClient{Interface: client, recorded: recorded},
+				kind:          id.Kind,
+				res:           res,
+				direction:     domain.BackendToCluster,
+				Strategy:      domain.PatchStrategy,
+				fieldManager:  config.DefaultFieldManager,
+				dryRun:        true,
+				ShadowObjects: NewMemoryShadowStore(),
+				callbacks: domain.Callbacks{
+					GetObject: func(context.Context, domain.KindName, []byte) error {
+						return errors.New("unexpected get object")
+					},
+				},
+			}
+			require.NoError(t, tt.write(context.TODO(), c))
+			// the diff is logged against the live object fetched by the write
+			var gets int
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "get" {
+					gets++
+				}
+			}
+			assert.Equal(t, 1, gets)
+			// writes only reach the cluster as dry-run applies
+			assert.Empty(t, recorded.deletes)
+			require.Len(t, recorded.applies, tt.wantApplies)
+			for _, opts := range recorded.applies {
+				assert.Equal(t, []string{metav1.DryRunAll}, opts.DryRun)
+			}
+			stored, err := client.Resource(res).Namespace(id.Namespace).Get(context.TODO(), id.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, live.Object["spec"], stored.Object["spec"])
+			_, ok := c.ShadowObjects.Get(id.String())
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestClient_writes_getLiveObjectOnce(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+	}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "test",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{"replicas": int64(1)},
+	}}
+	patch := []byte(`{"spec":{"replicas":2}}`)
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, c *Client) error
+	}{
+		{
+			name: "put",
+			write: func(ctx context.Context, c *Client) error {
+				return c.PutObject(ctx, id, []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"default"},"spec":{"replicas":2}}`))
+			},
+		},
+		{
+			name: "patch",
+			write: func(ctx context.Context, c *Client) error {
+				object, err := c.filterAndMarshal(ctx, live.DeepCopy())
+				require.NoError(t, err)
+				modified, err := utils.ApplyPatch(domain.MergePatch, object, patch)
+				require.NoError(t, err)
+				checksum, err := utils.CanonicalHash(modified)
+				require.NoError(t, err)
+				return c.PatchObject(ctx, id, checksum, domain.MergePatch, patch)
+			},
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, c *Client) error {
+				return c.DeleteObject(ctx, id)
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, writable := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s writable=%t", tt.name, writable), func(t *testing.T) {
+				client := fake.NewSimpleDynamicClient(runtime.NewScheme(), live.DeepCopy())
+				client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					var obj unstructured.Unstructured
+					require.NoError(t, obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()))
+					return true, &obj, nil
+				})
+				c := &Client{
+					client:        client,
+					kind:          id.Kind,
+					res:           res,
+					direction:     domain.ClusterToBackend,
+					Strategy:      domain.PatchStrategy,
+					fieldManager:  config.DefaultFieldManager,
+					ShadowObjects: NewMemoryShadowStore(),
+					callbacks: domain.Callbacks{
+						WriteFailed: func(context.Context, domain.KindName, domain.WriteFailureReason, string) error {
+							return nil
+						},
+					},
+				}
+				wantGets := 0
+				if writable {
+					c.direction = domain.BackendToCluster
+					wantGets = 1
+				}
+				err := tt.write(context.TODO(), c)
+				if writable {
+					require.NoError(t, err)
+				} else {
+					// refused before reading the cluster
+					assert.ErrorIs(t, err, errWriteNotAllowed)
+				}
+				var gets int
+				for _, action := range client.Actions() {
+					if action.GetVerb() == "get" {
+						gets++
+					}
+				}
+				assert.Equal(t, wantGets, gets)
+			})
+		}
+	}
+}
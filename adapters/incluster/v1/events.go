@@ -0,0 +1,165 @@
+package incluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// EventMsgIdAnnotation is set on recorded events, it contains the ID of the message that caused the change
+	EventMsgIdAnnotation = "synchronizer.kubescape.io/msg-id"
+	// EventAccountAnnotation is set on recorded events, it contains the account of the backend
+	EventAccountAnnotation = "synchronizer.kubescape.io/account"
+	// EventOperationAnnotation is set on recorded events, it contains the operation made by the synchronizer
+	EventOperationAnnotation = "synchronizer.kubescape.io/operation"
+
+	eventComponent = "synchronizer"
+	// clusterEventsNamespace receives the events of cluster-scoped objects deletions, as they have no namespace
+	clusterEventsNamespace = "default"
+	// maxEventRecorderEntries limits the number of objects we remember the last event of
+	maxEventRecorderEntries = 10000
+)
+
+var eventsResource = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// writeOperation is a change made to the cluster on behalf of the backend.
+type writeOperation string
+
+const (
+	operationPut    writeOperation = "put"
+	operationPatch  writeOperation = "patch"
+	operationDelete writeOperation = "delete"
+)
+
+var operationReasons = map[writeOperation]string{
+	operationPut:    "SynchronizerApplied",
+	operationPatch:  "SynchronizerPatched",
+	operationDelete: "SynchronizerDeleted",
+}
+
+// eventRecorder records Kubernetes Events for the changes made by the synchronizer,
+// so that they show up in kubectl describe. Events are rate limited per object.
+// A nil recorder records nothing.
+type eventRecorder struct {
+	client      dynamic.Interface
+	account     string
+	minInterval time.Duration
+	mu          sync.Mutex
+	last        map[string]time.Time
+	now         func() time.Time
+}
+
+func newEventRecorder(client dynamic.Interface, account string, minInterval time.Duration) *eventRecorder {
+	return &eventRecorder{
+		client:      client,
+		account:     account,
+		minInterval: minInterval,
+		last:        map[string]time.Time{},
+		now:         time.Now,
+	}
+}
+
+// recordWrite records an event on the object written by the synchronizer.
+func (r *eventRecorder) recordWrite(ctx context.Context, id domain.KindName, obj *unstructured.Unstructured, operation writeOperation) {
+	if r == nil || obj == nil {
+		return
+	}
+	involved := corev1.ObjectReference{
+		APIVersion:      obj.GetAPIVersion(),
+		Kind:            obj.GetKind(),
+		Namespace:       obj.GetNamespace(),
+		Name:            obj.GetName(),
+		UID:             obj.GetUID(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = clusterEventsNamespace
+	}
+	r.record(ctx, id, namespace, involved, operation, fmt.Sprintf("%s %s", operation, id.String()))
+}
+
+// recordDelete records an event on the namespace of the object deleted by the synchronizer.
+func (r *eventRecorder) recordDelete(ctx context.Context, id domain.KindName) {
+	if r == nil {
+		return
+	}
+	namespace := id.Namespace
+	if namespace == "" {
+		namespace = clusterEventsNamespace
+	}
+	involved := corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       namespace,
+	}
+	r.record(ctx, id, namespace, involved, operationDelete, fmt.Sprintf("%s %s", operationDelete, id.String()))
+}
+
+func (r *eventRecorder) record(ctx context.Context, id domain.KindName, namespace string, involved corev1.ObjectReference, operation writeOperation, action string) {
+	if !r.allow(id.String()) {
+		return
+	}
+	msgId, _ := ctx.Value(domain.ContextKeyMsgId).(string)
+	now := metav1.NewTime(r.now())
+	event := &corev1.Event{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", involved.Name, now.UnixNano()),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				EventMsgIdAnnotation:     msgId,
+				EventAccountAnnotation:   r.account,
+				EventOperationAnnotation: string(operation),
+			},
+		},
+		InvolvedObject: involved,
+		Reason:         operationReasons[operation],
+		Message:        fmt.Sprintf("Synchronizer %s for account %s (message %s)", action, r.account, msgId),
+		Source:         corev1.EventSource{Component: eventComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeNormal,
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event)
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("cannot convert event", helpers.Error(err), helpers.String("id", id.String()))
+		return
+	}
+	_, err = r.client.Resource(eventsResource).Namespace(namespace).Create(context.Background(), &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("cannot record event", helpers.Error(err), helpers.String("id", id.String()))
+	}
+}
+
+// allow returns true if no event was recorded for the object during the minimum interval.
+func (r *eventRecorder) allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if last, ok := r.last[key]; ok && now.Sub(last) < r.minInterval {
+		return false
+	}
+	if len(r.last) >= maxEventRecorderEntries {
+		for k, last := range r.last {
+			if now.Sub(last) >= r.minInterval {
+				delete(r.last, k)
+			}
+		}
+	}
+	r.last[key] = now
+	return true
+}
@@ -0,0 +1,140 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func recordedEvents(t *testing.T, client *fake.FakeDynamicClient) []*unstructured.Unstructured {
+	var events []*unstructured.Unstructured
+	for _, action := range client.Actions() {
+		if create, ok := action.(k8stesting.CreateAction); ok && action.GetResource() == eventsResource {
+			event, ok := create.GetObject().(*unstructured.Unstructured)
+			require.True(t, ok)
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestEventRecorder(t *testing.T) {
+	ctx := context.WithValue(context.TODO(), domain.ContextKeyMsgId, "msg-1")
+	kind := &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "test",
+			"namespace": "default",
+			"uid":       "1234",
+		},
+	}}
+	tests := []struct {
+		name          string
+		record        func(r *eventRecorder)
+		wantNamespace string
+		wantKind      string
+		wantName      string
+		wantReason    string
+		wantOperation string
+	}{
+		{
+			name: "put is recorded on the object",
+			record: func(r *eventRecorder) {
+				r.recordWrite(ctx, domain.KindName{Kind: kind, Namespace: "default", Name: "test"}, deployment, operationPut)
+			},
+			wantNamespace: "default",
+			wantKind:      "Deployment",
+			wantName:      "test",
+			wantReason:    "SynchronizerApplied",
+			wantOperation: "put",
+		},
+		{
+			name: "patch is recorded on the object",
+			record: func(r *eventRecorder) {
+				r.recordWrite(ctx, domain.KindName{Kind: kind, Namespace: "default", Name: "test"}, deployment, operationPatch)
+			},
+			wantNamespace: "default",
+			wantKind:      "Deployment",
+			wantName:      "test",
+			wantReason:    "SynchronizerPatched",
+			wantOperation: "patch",
+		},
+		{
+			name: "delete is recorded on the namespace",
+			record: func(r *eventRecorder) {
+				r.recordDelete(ctx, domain.KindName{Kind: kind, Namespace: "kubescape", Name: "test"})
+			},
+			wantNamespace: "kubescape",
+			wantKind:      "Namespace",
+			wantName:      "kubescape",
+			wantReason:    "SynchronizerDeleted",
+			wantOperation: "delete",
+		},
+		{
+			name: "cluster-scoped delete is recorded on the default namespace",
+			record: func(r *eventRecorder) {
+				r.recordDelete(ctx, domain.KindName{Kind: &domain.Kind{Version: "v1", Resource: "namespaces"}, Name: "test"})
+			},
+			wantNamespace: "default",
+			wantKind:      "Namespace",
+			wantName:      "default",
+			wantReason:    "SynchronizerDeleted",
+			wantOperation: "delete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			r := newEventRecorder(client, "account-1", time.Minute)
+			tt.record(r)
+			events := recordedEvents(t, client)
+			require.Len(t, events, 1)
+			event := events[0]
+			assert.Equal(t, tt.wantNamespace, event.GetNamespace())
+			kind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+			assert.Equal(t, tt.wantKind, kind)
+			name, _, _ := unstructured.NestedString(event.Object, "involvedObject", "name")
+			assert.Equal(t, tt.wantName, name)
+			reason, _, _ := unstructured.NestedString(event.Object, "reason")
+			assert.Equal(t, tt.wantReason, reason)
+			assert.Equal(t, map[string]string{
+				EventMsgIdAnnotation:     "msg-1",
+				EventAccountAnnotation:   "account-1",
+				EventOperationAnnotation: tt.wantOperation,
+			}, event.GetAnnotations())
+		})
+	}
+}
+
+func TestEventRecorder_rateLimit(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	r := newEventRecorder(client, "account-1", time.Minute)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	kind := &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}
+	first := domain.KindName{Kind: kind, Namespace: "default", Name: "first"}
+	second := domain.KindName{Kind: kind, Namespace: "default", Name: "second"}
+	r.recordDelete(context.TODO(), first)
+	r.recordDelete(context.TODO(), first)  // rate limited
+	r.recordDelete(context.TODO(), second) // other object
+	now = now.Add(time.Minute)
+	r.recordDelete(context.TODO(), first)
+	assert.Len(t, recordedEvents(t, client), 3)
+}
+
+func TestEventRecorder_nil(t *testing.T) {
+	var r *eventRecorder
+	assert.NotPanics(t, func() {
+		r.recordDelete(context.TODO(), domain.KindName{Name: "test"})
+	})
+}
@@ -0,0 +1,146 @@
+package incluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/kubescape/synchronizer/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+const (
+	// degradedAfterFailures is the number of consecutive failures after which a resource is degraded
+	degradedAfterFailures = 5
+	// degradedRetryInterval is the slow schedule degraded resources are retried on
+	degradedRetryInterval = 5 * time.Minute
+)
+
+// Health is the synchronization state of the watched resources, served on the health endpoint.
+type Health struct {
+	Degraded  bool                      `json:"degraded"`
+	Resources map[string]ResourceHealth `json:"resources"`
+}
+
+// ResourceHealth is the synchronization state of a resource.
+type ResourceHealth struct {
+	State   domain.ResourceState `json:"state"`
+	Message string               `json:"message,omitempty"`
+	Since   time.Time            `json:"since"`
+}
+
+// resourceHealth tracks the consecutive failures of a client to list or watch its resource.
+type resourceHealth struct {
+	mu       sync.Mutex
+	failures int
+	current  ResourceHealth
+}
+
+func newResourceHealth() *resourceHealth {
+	return &resourceHealth{current: ResourceHealth{State: domain.ResourceHealthy, Since: time.Now()}}
+}
+
+func (h *resourceHealth) get() ResourceHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.current
+}
+
+func (h *resourceHealth) degraded() bool {
+	return h.get().State == domain.ResourceDegraded
+}
+
+// isUnrecoverable returns true if retrying quickly is pointless: the resource is not served anymore,
+// e.g. its CRD was uninstalled, or we are not allowed to access it.
+func isUnrecoverable(err error) bool {
+	return apierrors.IsNotFound(err) ||
+		apierrors.IsForbidden(err) ||
+		apierrors.IsUnauthorized(err) ||
+		apierrors.IsMethodNotSupported(err) ||
+		meta.IsNoMatchError(err)
+}
+
+// healthBackOff retries degraded resources on a slow schedule.
+type healthBackOff struct {
+	backoff.BackOff
+	health *resourceHealth
+}
+
+func (b *healthBackOff) NextBackOff() time.Duration {
+	d := b.BackOff.NextBackOff()
+	if d != backoff.Stop && d < degradedRetryInterval && b.health.degraded() {
+		return degradedRetryInterval
+	}
+	return d
+}
+
+// newBackOff returns the backoff used to list and watch the resource, it stops when the context is done.
+func (c *Client) newBackOff(ctx context.Context) backoff.BackOff {
+	return backoff.WithContext(&healthBackOff{BackOff: utils.NewBackOff(), health: c.health}, ctx)
+}
+
+// Health returns the synchronization state of the resource.
+func (c *Client) Health() ResourceHealth {
+	return c.health.get()
+}
+
+// markFailure records a failure to list or watch the resource, which is degraded after several
+// consecutive failures, or right away if the failure is unrecoverable.
+func (c *Client) markFailure(ctx context.Context, err error) {
+	c.health.mu.Lock()
+	c.health.failures++
+	degrade := c.health.failures >= degradedAfterFailures || isUnrecoverable(err)
+	c.health.mu.Unlock()
+	if degrade {
+		c.setState(ctx, domain.ResourceDegraded, err.Error())
+	}
+}
+
+// markHealthy records a successful list or watch of the resource.
+func (c *Client) markHealthy(ctx context.Context) {
+	c.health.mu.Lock()
+	c.health.failures = 0
+	c.health.mu.Unlock()
+	c.setState(ctx, domain.ResourceHealthy, "")
+}
+
+// setState updates the state of the resource, and reports it to the backend when it changes.
+func (c *Client) setState(ctx context.Context, state domain.ResourceState, message string) {
+	c.health.mu.Lock()
+	changed := c.health.current.State != state
+	if changed {
+		c.health.current.Since = time.Now()
+	}
+	c.health.current.State = state
+	c.health.current.Message = message
+	c.health.mu.Unlock()
+	if !changed {
+		return
+	}
+	if state == domain.ResourceDegraded {
+		resourceDegradedGauge.WithLabelValues(c.res.Resource).Set(1)
+		logger.L().Ctx(ctx).Error("resource degraded, retrying on a slow schedule", helpers.String("resource", c.res.Resource),
+			helpers.String("reason", message), helpers.String("retry every", degradedRetryInterval.String()))
+	} else {
+		resourceDegradedGauge.WithLabelValues(c.res.Resource).Set(0)
+		logger.L().Ctx(ctx).Info("resource recovered", helpers.String("resource", c.res.Resource))
+	}
+	c.reportState(ctx)
+}
+
+// reportState sends the current state of the resource to the backend.
+func (c *Client) reportState(ctx context.Context) {
+	if c.callbacks.ResourceStatus == nil {
+		return
+	}
+	current := c.health.get()
+	ctx = utils.ContextFromGeneric(ctx, domain.Generic{})
+	if err := c.callbacks.ResourceStatus(ctx, *c.kind, current.State, current.Message); err != nil {
+		logger.L().Ctx(ctx).Error("cannot report resource status", helpers.Error(err), helpers.String("resource", c.res.Resource))
+	}
+}
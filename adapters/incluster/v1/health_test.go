@@ -0,0 +1,105 @@
+package incluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClient_markFailure(t *testing.T) {
+	gr := schema.GroupResource{Group: "spdx.softwarecomposition.kubescape.io", Resource: "applicationprofiles"}
+	tests := []struct {
+		name       string
+		errs       []error
+		healthy    bool // the resource recovers at the end
+		wantState  domain.ResourceState
+		wantReport []domain.ResourceState
+	}{
+		{
+			name:      "transient failure",
+			errs:      []error{errors.New("connection reset")},
+			wantState: domain.ResourceHealthy,
+		},
+		{
+			name: "repeated transient failures",
+			errs: []error{
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+			},
+			wantState:  domain.ResourceDegraded,
+			wantReport: []domain.ResourceState{domain.ResourceDegraded},
+		},
+		{
+			name:       "uninstalled CRD",
+			errs:       []error{apierrors.NewNotFound(gr, "")},
+			wantState:  domain.ResourceDegraded,
+			wantReport: []domain.ResourceState{domain.ResourceDegraded},
+		},
+		{
+			name:       "missing RBAC",
+			errs:       []error{apierrors.NewForbidden(gr, "", errors.New("forbidden")), apierrors.NewForbidden(gr, "", errors.New("forbidden"))},
+			wantState:  domain.ResourceDegraded,
+			wantReport: []domain.ResourceState{domain.ResourceDegraded},
+		},
+		{
+			name:       "recovered",
+			errs:       []error{apierrors.NewNotFound(gr, "")},
+			healthy:    true,
+			wantState:  domain.ResourceHealthy,
+			wantReport: []domain.ResourceState{domain.ResourceDegraded, domain.ResourceHealthy},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []domain.ResourceState
+			c := &Client{
+				kind:   &domain.Kind{Group: gr.Group, Version: "v1beta1", Resource: gr.Resource},
+				res:    gr.WithVersion("v1beta1"),
+				health: newResourceHealth(),
+				callbacks: domain.Callbacks{
+					ResourceStatus: func(_ context.Context, _ domain.Kind, state domain.ResourceState, _ string) error {
+						reports = append(reports, state)
+						return nil
+					},
+				},
+			}
+			for _, err := range tt.errs {
+				c.markFailure(context.TODO(), err)
+			}
+			if tt.healthy {
+				c.markHealthy(context.TODO())
+			}
+			assert.Equal(t, tt.wantState, c.Health().State)
+			assert.Equal(t, tt.wantReport, reports)
+		})
+	}
+}
+
+func Test_healthBackOff(t *testing.T) {
+	health := newResourceHealth()
+	b := &healthBackOff{BackOff: &backoff.ConstantBackOff{Interval: time.Second}, health: health}
+	assert.Equal(t, time.Second, b.NextBackOff())
+	health.current.State = domain.ResourceDegraded
+	assert.Equal(t, degradedRetryInterval, b.NextBackOff())
+}
+
+func TestAdapter_Health(t *testing.T) {
+	healthy := &Client{kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, health: newResourceHealth()}
+	degraded := &Client{kind: &domain.Kind{Group: "spdx.softwarecomposition.kubescape.io", Version: "v1beta1", Resource: "applicationprofiles"}, health: newResourceHealth()}
+	degraded.health.current = ResourceHealth{State: domain.ResourceDegraded, Message: "forbidden"}
+	a := &Adapter{watched: []*Client{healthy, degraded}}
+	health := a.Health()
+	assert.True(t, health.Degraded)
+	assert.Equal(t, domain.ResourceHealthy, health.Resources["apps/v1/deployments"].State)
+	assert.Equal(t, "forbidden", health.Resources["spdx.softwarecomposition.kubescape.io/v1beta1/applicationprofiles"].Message)
+}
@@ -0,0 +1,43 @@
+package incluster
+
+import (
+	"context"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// IgnoreKey is an annotation or label excluding an object from sync when set to "true",
+// cluster owners can use it without changing the synchronizer config
+const IgnoreKey = "synchronizer.kubescape.io/ignore"
+
+// isIgnored returns true if the object is annotated or labelled to be excluded from sync.
+func isIgnored(d *unstructured.Unstructured) bool {
+	return d.GetAnnotations()[IgnoreKey] == "true" || d.GetLabels()[IgnoreKey] == "true"
+}
+
+// handleIgnored handles a watch event for an ignored object, objects becoming ignored are deleted from the backend.
+func (c *Client) handleIgnored(ctx context.Context, id domain.KindName, eventType watch.EventType) {
+	key := id.String()
+	switch eventType {
+	case watch.Added:
+		c.ignored.Add(key)
+	case watch.Deleted:
+		c.ignored.Remove(key)
+	case watch.Modified:
+		if c.ignored.Contains(key) {
+			return
+		}
+		c.ignored.Add(key)
+		logger.L().Info("object is now ignored, deleting it from the backend", helpers.String("id", key))
+		if err := c.callbacks.DeleteObject(ctx, id); err != nil {
+			logger.L().Ctx(ctx).Error("cannot delete ignored resource", helpers.Error(err), helpers.String("id", key))
+		}
+		// remove from known resources
+		c.ShadowObjects.Delete(key)
+		c.forgetSent(id)
+	}
+}
@@ -0,0 +1,104 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func Test_isIgnored(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		want        bool
+	}{
+		{
+			name: "no annotation nor label",
+		},
+		{
+			name:        "annotation",
+			annotations: map[string]string{IgnoreKey: "true"},
+			want:        true,
+		},
+		{
+			name:   "label",
+			labels: map[string]string{IgnoreKey: "true"},
+			want:   true,
+		},
+		{
+			name:        "not true",
+			annotations: map[string]string{IgnoreKey: "false"},
+			labels:      map[string]string{IgnoreKey: "yes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			d.SetAnnotations(tt.annotations)
+			d.SetLabels(tt.labels)
+			assert.Equal(t, tt.want, isIgnored(d))
+		})
+	}
+}
+
+func TestClient_handleIgnored(t *testing.T) {
+	id := domain.KindName{
+		Kind:      &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"},
+		Name:      "test",
+		Namespace: "default",
+	}
+	tests := []struct {
+		name        string
+		events      []watch.EventType
+		wantDeletes int
+		wantIgnored bool
+	}{
+		{
+			name:        "existing ignored object is not deleted",
+			events:      []watch.EventType{watch.Added, watch.Modified},
+			wantIgnored: true,
+		},
+		{
+			name:        "object becoming ignored is deleted once",
+			events:      []watch.EventType{watch.Modified, watch.Modified},
+			wantDeletes: 1,
+			wantIgnored: true,
+		},
+		{
+			name:   "deleted ignored object is forgotten",
+			events: []watch.EventType{watch.Added, watch.Deleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deletes int
+			c := &Client{
+				ignored:       mapset.NewSet[string](),
+				ShadowObjects: NewMemoryShadowStore(),
+				Strategy:      domain.PatchStrategy,
+				callbacks: domain.Callbacks{
+					DeleteObject: func(_ context.Context, _ domain.KindName) error {
+						deletes++
+						return nil
+					},
+				},
+			}
+			c.ShadowObjects.Set(id.String(), []byte("{}"))
+			for _, eventType := range tt.events {
+				c.handleIgnored(context.TODO(), id, eventType)
+			}
+			assert.Equal(t, tt.wantDeletes, deletes)
+			assert.Equal(t, tt.wantIgnored, c.ignored.Contains(id.String()))
+			if tt.wantDeletes > 0 {
+				_, ok := c.ShadowObjects.Get(id.String())
+				assert.False(t, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package incluster
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	prometheusResourceLabel = "resource"
+	prometheusOutcomeLabel  = "outcome"
+	prometheusPolicyLabel   = "policy"
+
+	prometheusOutcomeLabelValueApplied         = "applied"
+	prometheusOutcomeLabelValueStatusApplied   = "status_applied"
+	prometheusOutcomeLabelValueForced          = "forced"
+	prometheusOutcomeLabelValueConflictSkipped = "conflict_skipped"
+	prometheusOutcomeLabelValueConflict        = "conflict"
+	prometheusOutcomeLabelValueRecreated       = "recreated"
+	prometheusOutcomeLabelValueRefused         = "refused"
+	prometheusOutcomeLabelValuePruned          = "pruned"
+	prometheusOutcomeLabelValueDryRun          = "dry_run"
+	prometheusOutcomeLabelValueError           = "error"
+	prometheusOutcomeLabelValueQueued          = "queued"
+	prometheusOutcomeLabelValueSucceeded       = "succeeded"
+	prometheusOutcomeLabelValueExhausted       = "exhausted"
+)
+
+var (
+	writesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_writes_count",
+		Help: "The total number of writes from the backend into the cluster, by outcome",
+	}, []string{prometheusResourceLabel, prometheusOutcomeLabel})
+	writeRetriesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_write_retries_count",
+		Help: "The total number of retried writes from the backend, by outcome",
+	}, []string{prometheusResourceLabel, prometheusOutcomeLabel})
+	cooldownEnqueuedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_cooldown_enqueued_count",
+		Help: "The total number of watch events put into the cooldown queue",
+	}, []string{prometheusResourceLabel})
+	cooldownEmittedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_cooldown_emitted_count",
+		Help: "The total number of watch events forwarded by the cooldown queue",
+	}, []string{prometheusResourceLabel})
+	cooldownCoalescingRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "synchronizer_incluster_cooldown_coalescing_ratio",
+		Help: "The share of watch events merged into a later one by the cooldown queue",
+	}, []string{prometheusResourceLabel})
+	suppressedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_suppressed_count",
+		Help: "The total number of modified objects not sent with the copy strategy, as only ignored fields changed",
+	}, []string{prometheusResourceLabel})
+	resourceDegradedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "synchronizer_incluster_resource_degraded",
+		Help: "Whether the resource cannot be listed or watched anymore and is retried on a slow schedule",
+	}, []string{prometheusResourceLabel})
+	oversizeCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "synchronizer_incluster_oversize_objects_count",
+		Help: "The total number of objects exceeding the maximum size, by applied policy",
+	}, []string{prometheusResourceLabel, prometheusPolicyLabel})
+	oversizeBytesHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "synchronizer_incluster_oversize_object_bytes",
+		Help:    "The marshalled size of objects exceeding the maximum size, before the policy is applied",
+		Buckets: prometheus.ExponentialBuckets(64*1024, 2, 10),
+	}, []string{prometheusResourceLabel})
+)
@@ -0,0 +1,108 @@
+package incluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OversizeAnnotation is set on objects sent without some of their fields because they exceed the maximum size,
+// it contains their original marshalled size in bytes
+const OversizeAnnotation = "synchronizer.kubescape.io/oversize"
+
+var errObjectTooLarge = errors.New("object too large")
+
+// identityFields are kept when projecting an oversize object
+var identityFields = map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+
+// handleOversize applies the oversize policy to an object whose marshalled size exceeds the maximum size.
+// It returns errObjectTooLarge when the object should not be sent.
+func (c *Client) handleOversize(ctx context.Context, d *unstructured.Unstructured, size int) ([]byte, error) {
+	policy := c.oversizePolicy
+	if policy == "" {
+		policy = domain.OversizeSkip
+	}
+	oversizeCounter.WithLabelValues(c.res.Resource, string(policy)).Inc()
+	oversizeBytesHistogram.WithLabelValues(c.res.Resource).Observe(float64(size))
+	var object []byte
+	var err error
+	switch policy {
+	case domain.OversizeMetadata:
+		object, err = projectOversize(d, size, c.maxObjectSize, false)
+	case domain.OversizeTruncate:
+		object, err = projectOversize(d, size, c.maxObjectSize, true)
+	default:
+		err = errObjectTooLarge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", err, size, c.maxObjectSize)
+	}
+	logger.L().Ctx(ctx).Warning("sending projection of oversize object",
+		helpers.String("resource", c.res.Resource),
+		helpers.String("namespace", d.GetNamespace()),
+		helpers.String("name", d.GetName()),
+		helpers.String("policy", string(policy)),
+		helpers.Int("size", size),
+		helpers.Int("projectedSize", len(object)))
+	return object, nil
+}
+
+// projectOversize marshals a copy of the object annotated with its original size, keeping only its identity fields.
+// When truncate is set, the largest top-level fields are dropped one by one until the object fits instead.
+func projectOversize(d *unstructured.Unstructured, size, maxSize int, truncate bool) ([]byte, error) {
+	projection := d.DeepCopy()
+	ann := projection.GetAnnotations()
+	if ann == nil {
+		ann = map[string]string{}
+	}
+	ann[OversizeAnnotation] = strconv.Itoa(size)
+	projection.SetAnnotations(ann)
+	if !truncate {
+		for field := range projection.Object {
+			if !identityFields[field] {
+				delete(projection.Object, field)
+			}
+		}
+	}
+	for {
+		object, err := projection.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		if len(object) <= maxSize {
+			return object, nil
+		}
+		field := largestField(projection.Object)
+		if field == "" {
+			return nil, errObjectTooLarge
+		}
+		delete(projection.Object, field)
+	}
+}
+
+// largestField returns the name of the largest top-level field that is not an identity field, or an empty string.
+func largestField(object map[string]interface{}) string {
+	var largest string
+	var largestSize int
+	for field, value := range object {
+		if identityFields[field] {
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			// cannot measure it, drop it first
+			return field
+		}
+		if len(b) > largestSize {
+			largest, largestSize = field, len(b)
+		}
+	}
+	return largest
+}
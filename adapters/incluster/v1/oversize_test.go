@@ -0,0 +1,104 @@
+package incluster
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func configMap() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "big",
+			"namespace": "default",
+		},
+		"data":       map[string]interface{}{"big": strings.Repeat("x", 2000)},
+		"binaryData": map[string]interface{}{"small": "eA=="},
+	}}
+}
+
+func TestClient_filterAndMarshal_oversize(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxObjectSize int
+		policy        domain.OversizePolicy
+		wantErr       bool
+		wantFields    []string
+		wantOversize  bool
+	}{
+		{
+			name:          "no limit",
+			maxObjectSize: 0,
+			wantFields:    []string{"apiVersion", "binaryData", "data", "kind", "metadata"},
+		},
+		{
+			name:          "under the limit",
+			maxObjectSize: 10000,
+			wantFields:    []string{"apiVersion", "binaryData", "data", "kind", "metadata"},
+		},
+		{
+			name:          "skip by default",
+			maxObjectSize: 1000,
+			wantErr:       true,
+		},
+		{
+			name:          "skip",
+			maxObjectSize: 1000,
+			policy:        domain.OversizeSkip,
+			wantErr:       true,
+		},
+		{
+			name:          "metadata only",
+			maxObjectSize: 1000,
+			policy:        domain.OversizeMetadata,
+			wantFields:    []string{"apiVersion", "kind", "metadata"},
+			wantOversize:  true,
+		},
+		{
+			name:          "truncate drops the largest fields",
+			maxObjectSize: 1000,
+			policy:        domain.OversizeTruncate,
+			wantFields:    []string{"apiVersion", "binaryData", "kind", "metadata"},
+			wantOversize:  true,
+		},
+		{
+			name:          "metadata too large",
+			maxObjectSize: 10,
+			policy:        domain.OversizeTruncate,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				res:            schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+				maxObjectSize:  tt.maxObjectSize,
+				oversizePolicy: tt.policy,
+			}
+			object, err := c.filterAndMarshal(context.TODO(), configMap())
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errObjectTooLarge)
+				return
+			}
+			require.NoError(t, err)
+			var got unstructured.Unstructured
+			require.NoError(t, json.Unmarshal(object, &got.Object))
+			var fields []string
+			for field := range got.Object {
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+			_, oversize := got.GetAnnotations()[OversizeAnnotation]
+			assert.Equal(t, tt.wantOversize, oversize)
+		})
+	}
+}
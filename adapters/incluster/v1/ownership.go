@@ -0,0 +1,176 @@
+package incluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/config"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/lru"
+)
+
+const (
+	// TopLevelOwnerAnnotation is set on children sent to the backend, it contains the JSON encoded owner reference of their top-level owner
+	TopLevelOwnerAnnotation = "synchronizer.kubescape.io/top-level-owner"
+	// maxOwnerDepth limits how far we follow owner references
+	maxOwnerDepth = 5
+	// maxOwnerCacheSize limits the number of resolved owners we keep in memory, the least recently used are evicted
+	maxOwnerCacheSize = 10000
+)
+
+// hasParent returns true if workload has a parent according to the ownership rules
+// based on https://github.com/kubescape/k8s-interface/blob/2855cc94bd7666b227ad9e5db5ca25cb895e6cee/k8sinterface/k8sdynamic.go#L219
+func hasParent(workload *unstructured.Unstructured, rules config.OwnershipRules) bool {
+	if workload == nil {
+		return false
+	}
+	// filter out non-controller workloads
+	if !slices.Contains(rules.Kinds, workload.GetKind()) {
+		return false
+	}
+	// check if workload has owner
+	ownerReferences := workload.GetOwnerReferences() // OwnerReferences in workload
+	if len(ownerReferences) > 0 {
+		for _, owner := range ownerReferences {
+			if slices.ContainsFunc(rules.Owners, func(o config.OwnerMatch) bool {
+				return o.Matches(owner.APIVersion, owner.Kind)
+			}) {
+				return true
+			}
+		}
+		return false
+	}
+	// check if workload is Pod with pod-template-hash label
+	if rules.PodTemplateHash && workload.GetKind() == "Pod" {
+		if podLabels := workload.GetLabels(); podLabels != nil {
+			if podHash, ok := podLabels["pod-template-hash"]; ok && podHash != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ownerResolver follows owner references up to the top-level owner of an object.
+// Resolved owners are kept and never resolved again, so that a transient failure, e.g. a REST mapper miss,
+// does not remove the annotation of children already sent.
+type ownerResolver struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper // maps owner kinds to their resource and scope, nil disables the resolution
+	cache  *lru.Cache      // owner UID -> top-level owner
+}
+
+func newOwnerResolver(client dynamic.Interface, mapper meta.RESTMapper) *ownerResolver {
+	return &ownerResolver{
+		client: client,
+		mapper: mapper,
+		cache:  lru.New(maxOwnerCacheSize),
+	}
+}
+
+// controllerOf returns the controller reference of an object, or its first owner reference.
+func controllerOf(owners []metav1.OwnerReference) (metav1.OwnerReference, bool) {
+	if len(owners) == 0 {
+		return metav1.OwnerReference{}, false
+	}
+	for _, owner := range owners {
+		if owner.Controller != nil && *owner.Controller {
+			return owner, true
+		}
+	}
+	return owners[0], true
+}
+
+// topLevelOwner returns the reference of the top-level owner of an object.
+// It returns false when the object has no owner, or when its top-level owner cannot be resolved yet,
+// so that children are not sent with a different owner from one sync to the next.
+func (r *ownerResolver) topLevelOwner(ctx context.Context, d *unstructured.Unstructured) (metav1.OwnerReference, bool) {
+	owner, ok := controllerOf(d.GetOwnerReferences())
+	if !ok {
+		return metav1.OwnerReference{}, false
+	}
+	if cached, ok := r.cache.Get(string(owner.UID)); ok {
+		return cached.(metav1.OwnerReference), true
+	}
+	top := owner
+	namespace := d.GetNamespace()
+	for i := 0; ; i++ {
+		if i == maxOwnerDepth {
+			logger.L().Debug("owner chain too deep, not annotating top-level owner", helpers.String("name", d.GetName()),
+				helpers.String("namespace", d.GetNamespace()))
+			return metav1.OwnerReference{}, false
+		}
+		obj, err := r.getOwner(ctx, top, namespace)
+		if err != nil {
+			// do not annotate partial results, the owner might be fetched next time
+			logger.L().Debug("cannot get owner, not annotating top-level owner", helpers.Error(err), helpers.String("name", d.GetName()),
+				helpers.String("namespace", d.GetNamespace()), helpers.String("owner", top.Name))
+			return metav1.OwnerReference{}, false
+		}
+		next, ok := controllerOf(obj.GetOwnerReferences())
+		if !ok {
+			break
+		}
+		top = next
+		namespace = obj.GetNamespace()
+	}
+	r.cache.Add(string(owner.UID), top)
+	return top, true
+}
+
+// getOwner fetches an owner, cluster-scoped owners are fetched without the namespace of their child.
+func (r *ownerResolver) getOwner(ctx context.Context, owner metav1.OwnerReference, namespace string) (*unstructured.Unstructured, error) {
+	if r.mapper == nil {
+		return nil, fmt.Errorf("no REST mapper to resolve owners")
+	}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("parse owner API version: %w", err)
+	}
+	mapping, err := r.mapper.RESTMapping(gv.WithKind(owner.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, fmt.Errorf("map owner kind: %w", err)
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return r.client.Resource(mapping.Resource).Get(ctx, owner.Name, metav1.GetOptions{})
+	}
+	return r.client.Resource(mapping.Resource).Namespace(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+}
+
+// annotateTopLevelOwner adds the top-level owner annotation to a child object.
+func (r *ownerResolver) annotateTopLevelOwner(ctx context.Context, d *unstructured.Unstructured) error {
+	owner, ok := r.topLevelOwner(ctx, d)
+	if !ok {
+		return nil
+	}
+	value, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	ann := d.GetAnnotations()
+	if ann == nil {
+		ann = map[string]string{}
+	}
+	ann[TopLevelOwnerAnnotation] = string(value)
+	d.SetAnnotations(ann)
+	return nil
+}
+
+// removeTopLevelOwner removes the top-level owner annotation from an object written from the backend,
+// it is only added to the objects sent to the backend and must not end up in the cluster.
+func removeTopLevelOwner(d *unstructured.Unstructured) {
+	ann := d.GetAnnotations()
+	if _, ok := ann[TopLevelOwnerAnnotation]; !ok {
+		return
+	}
+	delete(ann, TopLevelOwnerAnnotation)
+	d.SetAnnotations(ann)
+}
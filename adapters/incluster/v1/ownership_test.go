@@ -0,0 +1,206 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/synchronizer/config"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func workload(kind string, labels map[string]interface{}, owners ...map[string]interface{}) *unstructured.Unstructured {
+	var ownerReferences []interface{}
+	for _, o := range owners {
+		ownerReferences = append(ownerReferences, o)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": kind,
+		"metadata": map[string]interface{}{
+			"name":            "name",
+			"labels":          labels,
+			"ownerReferences": ownerReferences,
+		},
+	}}
+}
+
+func Test_hasParent(t *testing.T) {
+	replicaSetOwner := map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "rs", "uid": "1"}
+	rolloutOwner := map[string]interface{}{"apiVersion": "argoproj.io/v1alpha1", "kind": "Rollout", "name": "ro", "uid": "2"}
+	rolloutRules := config.OwnershipRules{
+		Kinds:  []string{"Pod", "ReplicaSet"},
+		Owners: []config.OwnerMatch{{APIVersion: "apps/v1"}, {APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"}},
+	}
+	tests := []struct {
+		name     string
+		workload *unstructured.Unstructured
+		rules    config.OwnershipRules
+		want     bool
+	}{
+		{
+			name:     "nil workload",
+			workload: nil,
+			rules:    config.DefaultOwnershipRules,
+		},
+		{
+			name:     "deployment is never a child",
+			workload: workload("Deployment", nil, replicaSetOwner),
+			rules:    config.DefaultOwnershipRules,
+		},
+		{
+			name:     "pod owned by replicaset",
+			workload: workload("Pod", nil, replicaSetOwner),
+			rules:    config.DefaultOwnershipRules,
+			want:     true,
+		},
+		{
+			name:     "pod with pod-template-hash",
+			workload: workload("Pod", map[string]interface{}{"pod-template-hash": "abc"}),
+			rules:    config.DefaultOwnershipRules,
+			want:     true,
+		},
+		{
+			name:     "pod-template-hash ignored by custom rules",
+			workload: workload("Pod", map[string]interface{}{"pod-template-hash": "abc"}),
+			rules:    rolloutRules,
+		},
+		{
+			name:     "replicaset owned by rollout with default rules",
+			workload: workload("ReplicaSet", nil, rolloutOwner),
+			rules:    config.DefaultOwnershipRules,
+		},
+		{
+			name:     "replicaset owned by rollout with custom rules",
+			workload: workload("ReplicaSet", nil, rolloutOwner),
+			rules:    rolloutRules,
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasParent(tt.workload, tt.rules))
+		})
+	}
+}
+
+func ownerObject(apiVersion, kind, name, namespace string, owners ...map[string]interface{}) *unstructured.Unstructured {
+	o := workload(kind, nil, owners...)
+	o.SetAPIVersion(apiVersion)
+	o.SetName(name)
+	o.SetNamespace(namespace)
+	o.SetUID(types.UID(name + "-uid"))
+	return o
+}
+
+func ownerRef(apiVersion, kind, name string) map[string]interface{} {
+	return map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "name": name, "uid": name + "-uid", "controller": true}
+}
+
+func TestOwnerResolver_topLevelOwner(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot)
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		child   *unstructured.Unstructured
+		want    string
+		wantOk  bool
+	}{
+		{
+			name: "namespaced owners",
+			objects: []runtime.Object{
+				ownerObject("apps/v1", "ReplicaSet", "rs", "ns", ownerRef("apps/v1", "Deployment", "deploy")),
+				ownerObject("apps/v1", "Deployment", "deploy", "ns"),
+			},
+			child:  ownerObject("v1", "Pod", "pod", "ns", ownerRef("apps/v1", "ReplicaSet", "rs")),
+			want:   "deploy",
+			wantOk: true,
+		},
+		{
+			name:    "cluster-scoped owner",
+			objects: []runtime.Object{ownerObject("v1", "Node", "node", "")},
+			child:   ownerObject("v1", "Pod", "pod", "ns", ownerRef("v1", "Node", "node")),
+			want:    "node",
+			wantOk:  true,
+		},
+		{
+			name:    "missing owner",
+			objects: []runtime.Object{ownerObject("apps/v1", "ReplicaSet", "rs", "ns", ownerRef("apps/v1", "Deployment", "deploy"))},
+			child:   ownerObject("v1", "Pod", "pod", "ns", ownerRef("apps/v1", "ReplicaSet", "rs")),
+		},
+		{
+			name:  "unknown owner kind",
+			child: ownerObject("v1", "Pod", "pod", "ns", ownerRef("example.com/v1", "Custom", "custom")),
+		},
+		{
+			name:  "no owner",
+			child: ownerObject("v1", "Pod", "pod", "ns"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newOwnerResolver(fake.NewSimpleDynamicClient(runtime.NewScheme(), tt.objects...), mapper)
+			owner, ok := r.topLevelOwner(context.TODO(), tt.child)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, owner.Name)
+			// children are only annotated with resolved owners
+			require.NoError(t, r.annotateTopLevelOwner(context.TODO(), tt.child))
+			_, annotated := tt.child.GetAnnotations()[TopLevelOwnerAnnotation]
+			assert.Equal(t, tt.wantOk, annotated)
+		})
+	}
+}
+
+func TestOwnerResolver_keepsResolvedOwner(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		ownerObject("apps/v1", "ReplicaSet", "rs", "ns", ownerRef("apps/v1", "Deployment", "deploy")),
+		ownerObject("apps/v1", "Deployment", "deploy", "ns"))
+	r := newOwnerResolver(client, mapper)
+	child := ownerObject("v1", "Pod", "pod", "ns", ownerRef("apps/v1", "ReplicaSet", "rs"))
+	owner, ok := r.topLevelOwner(context.TODO(), child)
+	require.True(t, ok)
+	assert.Equal(t, "deploy", owner.Name)
+	// owners cannot be resolved anymore, the resolved one is kept
+	r.mapper = meta.NewDefaultRESTMapper(nil)
+	owner, ok = r.topLevelOwner(context.TODO(), child)
+	assert.True(t, ok)
+	assert.Equal(t, "deploy", owner.Name)
+}
+
+func TestClient_PutObject_removesTopLevelOwner(t *testing.T) {
+	res := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	id := domain.KindName{Kind: &domain.Kind{Version: "v1", Resource: "pods"}, Name: "pod", Namespace: "ns"}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	var applied *unstructured.Unstructured
+	client.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		applied = &unstructured.Unstructured{}
+		require.NoError(t, applied.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()))
+		return true, applied, nil
+	})
+	c := &Client{
+		client:        client,
+		kind:          id.Kind,
+		res:           res,
+		direction:     domain.BackendToCluster,
+		Strategy:      domain.CopyStrategy,
+		fieldManager:  config.DefaultFieldManager,
+		ShadowObjects: NewMemoryShadowStore(),
+	}
+	object := []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"ns","annotations":{"a":"b","` + TopLevelOwnerAnnotation + `":"{}"}}}`)
+	require.NoError(t, c.PutObject(context.TODO(), id, object))
+	require.NotNil(t, applied)
+	assert.Equal(t, map[string]string{"a": "b"}, applied.GetAnnotations())
+}
@@ -0,0 +1,118 @@
+package incluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubescape/synchronizer/config"
+	"go.uber.org/multierr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// PermissionReport tells which verbs the synchronizer is missing on a configured resource.
+type PermissionReport struct {
+	Resource config.Resource
+	Missing  []string
+	Err      error // some reviews failed, the resource is assumed to work
+}
+
+// Allowed returns true if no verb is known to be missing.
+func (r PermissionReport) Allowed() bool {
+	return len(r.Missing) == 0
+}
+
+func (r PermissionReport) String() string {
+	if r.Allowed() {
+		return "all permissions granted"
+	}
+	return fmt.Sprintf("missing permissions: %s", strings.Join(r.Missing, ", "))
+}
+
+// permission is a verb allowed on a resource or one of its subresources.
+type permission struct {
+	group       string
+	version     string
+	resource    string
+	subresource string
+	verb        string
+}
+
+// describe returns the verb, followed by the subresource or the resource when not the configured one.
+func (p permission) describe(r config.Resource) string {
+	switch {
+	case p.group != r.Group || p.resource != r.Resource:
+		return p.verb + " " + p.resource
+	case p.subresource != "":
+		return p.verb + " " + p.subresource
+	}
+	return p.verb
+}
+
+// requiredPermissions returns the permissions a resource needs: watched resources are listed, watched and fetched,
+// writable ones are fetched, created, updated, patched and deleted, with the status, events and namespaces
+// written by the enabled features.
+func requiredPermissions(cfg config.InCluster, r config.Resource) []permission {
+	var verbs []string
+	if r.Direction.Watched() {
+		verbs = append(verbs, "list", "watch", "get")
+	}
+	if r.Direction.Writable() {
+		if !r.Direction.Watched() {
+			verbs = append(verbs, "get")
+		}
+		verbs = append(verbs, "create", "update", "patch", "delete")
+	}
+	permissions := make([]permission, 0, len(verbs))
+	for _, verb := range verbs {
+		permissions = append(permissions, permission{group: r.Group, version: r.Version, resource: r.Resource, verb: verb})
+	}
+	if !r.Direction.Writable() {
+		return permissions
+	}
+	if r.SyncStatus {
+		permissions = append(permissions, permission{group: r.Group, version: r.Version, resource: r.Resource, subresource: "status", verb: "patch"})
+	}
+	if cfg.Events != nil && cfg.Events.Enabled {
+		permissions = append(permissions, permission{group: eventsResource.Group, version: eventsResource.Version, resource: eventsResource.Resource, verb: "create"})
+	}
+	if cfg.CreateNamespaces {
+		permissions = append(permissions,
+			permission{group: namespacesResource.Group, version: namespacesResource.Version, resource: namespacesResource.Resource, verb: "get"},
+			permission{group: namespacesResource.Group, version: namespacesResource.Version, resource: namespacesResource.Resource, verb: "create"})
+	}
+	return permissions
+}
+
+// CheckPermissions reviews with SelfSubjectAccessReviews whether the synchronizer can access the configured
+// resources in all namespaces.
+func CheckPermissions(ctx context.Context, reviews authorizationv1client.SelfSubjectAccessReviewInterface, cfg config.InCluster) []PermissionReport {
+	reports := make([]PermissionReport, 0, len(cfg.Resources))
+	for _, r := range cfg.Resources {
+		report := PermissionReport{Resource: r}
+		for _, p := range requiredPermissions(cfg, r) {
+			review, err := reviews.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Group:       p.group,
+						Version:     p.version,
+						Resource:    p.resource,
+						Subresource: p.subresource,
+						Verb:        p.verb,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				report.Err = multierr.Append(report.Err, fmt.Errorf("review %s permission: %w", p.describe(r), err))
+				continue
+			}
+			if !review.Status.Allowed {
+				report.Missing = append(report.Missing, p.describe(r))
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
@@ -0,0 +1,125 @@
+package incluster
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/kubescape/synchronizer/config"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	deployments := config.Resource{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "copy"}
+	writable := config.Resource{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "copy", Direction: domain.Bidirectional}
+	unwatched := config.Resource{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "copy", Direction: domain.BackendToCluster}
+	withStatus := unwatched
+	withStatus.SyncStatus = true
+	tests := []struct {
+		name        string
+		cfg         config.InCluster
+		resource    config.Resource
+		granted     []string // verbs on the resource, followed by the subresource or other resource
+		reviewErr   error
+		wantMissing []string
+		wantErr     bool
+	}{
+		{
+			name:     "read-only resource allowed",
+			resource: deployments,
+			granted:  []string{"list", "watch", "get"},
+		},
+		{
+			name:        "watch missing",
+			resource:    deployments,
+			granted:     []string{"list", "get"},
+			wantMissing: []string{"watch"},
+		},
+		{
+			name:        "writable resource missing write verbs",
+			resource:    writable,
+			granted:     []string{"list", "watch", "get", "patch"},
+			wantMissing: []string{"create", "update", "delete"},
+		},
+		{
+			name:     "unwatched resource is not listed nor watched",
+			resource: unwatched,
+			granted:  []string{"get", "create", "update", "patch", "delete"},
+		},
+		{
+			name:        "enabled write features",
+			cfg:         config.InCluster{CreateNamespaces: true, Events: &config.Events{Enabled: true}},
+			resource:    withStatus,
+			granted:     []string{"get", "create", "update", "patch", "delete"},
+			wantMissing: []string{"patch status", "create events", "get namespaces", "create namespaces"},
+		},
+		{
+			name:     "disabled events",
+			cfg:      config.InCluster{Events: &config.Events{}},
+			resource: unwatched,
+			granted:  []string{"get", "create", "update", "patch", "delete"},
+		},
+		{
+			name:      "review failed",
+			resource:  deployments,
+			reviewErr: errors.New("boom"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if tt.reviewErr != nil {
+					return true, nil, tt.reviewErr
+				}
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				assert.Empty(t, attributes.Namespace)
+				permission := attributes.Verb
+				switch {
+				case attributes.Resource != tt.resource.Resource:
+					permission += " " + attributes.Resource
+				case attributes.Subresource != "":
+					permission += " " + attributes.Subresource
+				default:
+					assert.Equal(t, tt.resource.Group, attributes.Group)
+				}
+				review.Status.Allowed = slices.Contains(tt.granted, permission)
+				return true, review, nil
+			})
+			tt.cfg.Resources = []config.Resource{tt.resource}
+			reports := CheckPermissions(context.TODO(), clientset.AuthorizationV1().SelfSubjectAccessReviews(), tt.cfg)
+			assert.Len(t, reports, 1)
+			assert.Equal(t, tt.wantMissing, reports[0].Missing)
+			assert.Equal(t, tt.wantErr, reports[0].Err != nil)
+			assert.Equal(t, len(tt.wantMissing) == 0, reports[0].Allowed())
+		})
+	}
+}
+
+func TestAdapter_SkipUnauthorized(t *testing.T) {
+	r := config.Resource{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "copy"}
+	a := NewInClusterAdapter(config.InCluster{Resources: []config.Resource{r}}, nil)
+	a.SkipUnauthorized([]PermissionReport{{Resource: r, Missing: []string{"watch"}}})
+	var reported []string
+	a.RegisterCallbacks(context.TODO(), domain.Callbacks{
+		PermissionReport: func(_ context.Context, kind domain.Kind, missing []string, _ string) error {
+			assert.Equal(t, "apps/v1/deployments", kind.String())
+			reported = missing
+			return nil
+		},
+	})
+	assert.NoError(t, a.Start(context.TODO()))
+	assert.Equal(t, []string{"watch"}, reported)
+	health := a.Health()
+	assert.True(t, health.Degraded)
+	assert.Equal(t, domain.ResourceDegraded, health.Resources["apps/v1/deployments"].State)
+	assert.Equal(t, "missing permissions: watch", health.Resources["apps/v1/deployments"].Message)
+}
@@ -0,0 +1,83 @@
+package incluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ManagedAccountLabel is set on objects created from the backend, it contains the owning account
+	ManagedAccountLabel = "synchronizer.kubescape.io/account"
+	// ManagedSourceLabel is set on objects created from the backend, it contains the sync source
+	ManagedSourceLabel = "synchronizer.kubescape.io/source"
+	// ManagedSourceBackend is the sync source of objects written from the backend
+	ManagedSourceBackend = "backend"
+)
+
+// setManagedLabels labels an object written from the backend, so that it can be pruned once the backend stops tracking it.
+func (c *Client) setManagedLabels(obj *unstructured.Unstructured) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ManagedAccountLabel] = c.account
+	labels[ManagedSourceLabel] = ManagedSourceBackend
+	obj.SetLabels(labels)
+}
+
+// removeManagedLabels removes the labels set by setManagedLabels, they are not sent back to the backend
+// so that the checksums of objects written from the backend do not change.
+// Labels set for another account are kept, the object is not ours.
+func (c *Client) removeManagedLabels(d *unstructured.Unstructured) {
+	if !c.isManaged(d) {
+		return
+	}
+	labels := d.GetLabels()
+	delete(labels, ManagedAccountLabel)
+	delete(labels, ManagedSourceLabel)
+	d.SetLabels(labels)
+}
+
+// isManaged returns true if the object was created from the backend of our account.
+func (c *Client) isManaged(d *unstructured.Unstructured) bool {
+	labels := d.GetLabels()
+	return c.account != "" && labels[ManagedAccountLabel] == c.account && labels[ManagedSourceLabel] == ManagedSourceBackend
+}
+
+// canPrune returns true if the object was created by the synchronizer and can be deleted when the backend no longer lists it.
+func (c *Client) canPrune(d *unstructured.Unstructured) bool {
+	return c.prune && c.direction.Writable() && c.isManaged(d) && !isIgnored(d)
+}
+
+// pruneObject deletes an object created from the backend that the backend no longer lists.
+func (c *Client) pruneObject(ctx context.Context, d *unstructured.Unstructured) error {
+	id := domain.KindName{
+		Kind:            c.kind,
+		Name:            d.GetName(),
+		Namespace:       d.GetNamespace(),
+		ResourceVersion: domain.ToResourceVersion(d.GetResourceVersion()),
+		UID:             string(d.GetUID()),
+	}
+	if c.dryRun {
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueDryRun).Inc()
+		logger.L().Ctx(ctx).Info("dry-run: object would be pruned", helpers.String("id", id.String()))
+		return nil
+	}
+	logger.L().Ctx(ctx).Info("pruning object no longer listed by the backend", helpers.String("id", id.String()))
+	err := c.client.Resource(c.res).Namespace(id.Namespace).Delete(context.Background(), id.Name, metav1.DeleteOptions{Preconditions: deletePreconditions(id)})
+	if err != nil {
+		writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueError).Inc()
+		return fmt.Errorf("prune resource: %w", err)
+	}
+	writesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValuePruned).Inc()
+	// remove from known resources
+	c.ShadowObjects.Delete(id.String())
+	c.events.recordDelete(ctx, id)
+	return nil
+}
@@ -0,0 +1,192 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestClient_canPrune(t *testing.T) {
+	managed := map[string]string{ManagedAccountLabel: "account-1", ManagedSourceLabel: ManagedSourceBackend}
+	tests := []struct {
+		name      string
+		prune     bool
+		direction domain.Direction
+		labels    map[string]string
+		ignored   bool
+		want      bool
+	}{
+		{
+			name:      "managed object",
+			prune:     true,
+			direction: domain.Bidirectional,
+			labels:    managed,
+			want:      true,
+		},
+		{
+			name:      "pruning disabled",
+			direction: domain.Bidirectional,
+			labels:    managed,
+		},
+		{
+			name:      "read-only resource",
+			prune:     true,
+			direction: domain.ClusterToBackend,
+			labels:    managed,
+		},
+		{
+			name:      "user-created object",
+			prune:     true,
+			direction: domain.Bidirectional,
+		},
+		{
+			name:      "other account",
+			prune:     true,
+			direction: domain.Bidirectional,
+			labels:    map[string]string{ManagedAccountLabel: "account-2", ManagedSourceLabel: ManagedSourceBackend},
+		},
+		{
+			name:      "no account label",
+			prune:     true,
+			direction: domain.Bidirectional,
+			labels:    map[string]string{ManagedSourceLabel: ManagedSourceBackend},
+		},
+		{
+			name:      "ignored object",
+			prune:     true,
+			direction: domain.Bidirectional,
+			labels:    managed,
+			ignored:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{account: "account-1", prune: tt.prune, direction: tt.direction}
+			d := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			d.SetLabels(tt.labels)
+			if tt.ignored {
+				d.SetAnnotations(map[string]string{IgnoreKey: "true"})
+			}
+			assert.Equal(t, tt.want, c.canPrune(d))
+		})
+	}
+}
+
+func TestClient_PutObject_managedLabels(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	userCreated := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "user",
+			"namespace": "default",
+		},
+	}}
+	tests := []struct {
+		name       string
+		objectName string
+		wantLabels bool
+	}{
+		{
+			name:       "created objects are labelled",
+			objectName: "new",
+			wantLabels: true,
+		},
+		{
+			name:       "user-created objects are not labelled",
+			objectName: "user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(runtime.NewScheme(), userCreated.DeepCopy())
+			c := &Client{
+				account:   "account-1",
+				client:    client,
+				res:       res,
+				kind:      &domain.Kind{Group: res.Group, Version: res.Version, Resource: res.Resource},
+				direction: domain.Bidirectional,
+			}
+			id := domain.KindName{Kind: c.kind, Name: tt.objectName, Namespace: "default"}
+			object := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"` + tt.objectName + `","namespace":"default"}}`)
+			// the fake client does not support apply, only check the labels sent
+			_ = c.PutObject(context.TODO(), id, object)
+			var applied *unstructured.Unstructured
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "patch" {
+					patch := action.(interface{ GetPatch() []byte }).GetPatch()
+					applied = &unstructured.Unstructured{}
+					require.NoError(t, applied.UnmarshalJSON(patch))
+				}
+			}
+			require.NotNil(t, applied)
+			_, ok := applied.GetLabels()[ManagedSourceLabel]
+			assert.Equal(t, tt.wantLabels, ok)
+		})
+	}
+}
+
+func TestClient_removeManagedLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "managed by our account",
+			labels: map[string]string{"app": "test", ManagedAccountLabel: "account-1", ManagedSourceLabel: ManagedSourceBackend},
+			want:   map[string]string{"app": "test"},
+		},
+		{
+			name:   "managed by another account",
+			labels: map[string]string{"app": "test", ManagedAccountLabel: "account-2", ManagedSourceLabel: ManagedSourceBackend},
+			want:   map[string]string{"app": "test", ManagedAccountLabel: "account-2", ManagedSourceLabel: ManagedSourceBackend},
+		},
+		{
+			name:   "not managed",
+			labels: map[string]string{"app": "test"},
+			want:   map[string]string{"app": "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{account: "account-1"}
+			d := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			d.SetLabels(tt.labels)
+			c.removeManagedLabels(d)
+			assert.Equal(t, tt.want, d.GetLabels())
+		})
+	}
+}
+
+func TestClient_pruneObject(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	d := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "test",
+			"namespace":       "default",
+			"resourceVersion": "5",
+			"uid":             "1234",
+		},
+	}}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), d.DeepCopy())
+	c := &Client{
+		client:        client,
+		res:           res,
+		kind:          &domain.Kind{Group: res.Group, Version: res.Version, Resource: res.Resource},
+		ShadowObjects: NewMemoryShadowStore(),
+	}
+	require.NoError(t, c.pruneObject(context.TODO(), d))
+	_, err := client.Resource(res).Namespace("default").Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Error(t, err)
+}
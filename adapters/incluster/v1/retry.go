@@ -0,0 +1,176 @@
+package incluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/kubescape/synchronizer/utils"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 5 * time.Minute
+	// retryQPS and retryBurst limit the retries of all the clients of an adapter
+	retryQPS   = 10
+	retryBurst = 100
+)
+
+// pendingWrite is the latest failed write from the backend for an object, waiting to be retried.
+type pendingWrite struct {
+	id    domain.KindName
+	msgId string
+	depth int
+	write func(ctx context.Context) error
+}
+
+// writeRetries retries failed writes from the backend with an exponential backoff per object,
+// only the latest write of each object is retried.
+type writeRetries struct {
+	queue       workqueue.RateLimitingInterface
+	limiter     *rate.Limiter
+	maxAttempts int
+	start       sync.Once
+	mu          sync.Mutex
+	pending     map[string]*pendingWrite
+}
+
+// newRetryLimiter returns the limit shared by the retries of several clients,
+// so that an outage spanning many resources does not result in a burst of writes.
+func newRetryLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(retryQPS), retryBurst)
+}
+
+// newWriteRetries returns the retries of a client, limited per object and by the shared limiter.
+func newWriteRetries(maxAttempts int, limiter *rate.Limiter) *writeRetries {
+	rateLimiter := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: limiter},
+	)
+	return &writeRetries{
+		queue:       workqueue.NewRateLimitingQueue(rateLimiter),
+		limiter:     limiter,
+		maxAttempts: maxAttempts,
+		pending:     map[string]*pendingWrite{},
+	}
+}
+
+// isRetriable returns true if a write failed because of a transient error:
+// API server timeouts and throttling, webhook timeouts, or network errors.
+func isRetriable(err error) bool {
+	var netErr net.Error
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsUnexpectedServerError(err) ||
+		errors.As(err, &netErr)
+}
+
+// retryOnFailure schedules a retry of a failed write if its error is transient,
+// it replaces any pending retry of the same object.
+func (c *Client) retryOnFailure(ctx context.Context, id domain.KindName, err error, write func(ctx context.Context) error) {
+	if err == nil || !isRetriable(err) || c.retries == nil {
+		return
+	}
+	key := id.String()
+	msgId, _ := ctx.Value(domain.ContextKeyMsgId).(string)
+	depth, _ := ctx.Value(domain.ContextKeyDepth).(int)
+	c.retries.mu.Lock()
+	c.retries.pending[key] = &pendingWrite{id: id, msgId: msgId, depth: depth, write: write}
+	c.retries.mu.Unlock()
+	writeRetriesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueQueued).Inc()
+	logger.L().Ctx(ctx).Warning("write failed, retrying later", helpers.Error(err), helpers.String("id", key))
+	c.retries.queue.AddRateLimited(key)
+}
+
+// cancelRetry drops the pending retry of an object, as a newer write supersedes it.
+func (c *Client) cancelRetry(id domain.KindName) {
+	if c.retries == nil {
+		return
+	}
+	key := id.String()
+	c.retries.mu.Lock()
+	defer c.retries.mu.Unlock()
+	if _, ok := c.retries.pending[key]; ok {
+		delete(c.retries.pending, key)
+		c.retries.queue.Forget(key)
+	}
+}
+
+// startRetries starts the worker retrying failed writes, whatever the direction of the resource.
+// It is started once, even if the client is restarted.
+func (c *Client) startRetries(ctx context.Context) {
+	if c.retries == nil {
+		return
+	}
+	c.retries.start.Do(func() {
+		go c.processRetries(ctx)
+	})
+}
+
+// processRetries retries the failed writes until the queue is shut down.
+func (c *Client) processRetries(ctx context.Context) {
+	for {
+		key, shutdown := c.retries.queue.Get()
+		if shutdown {
+			return
+		}
+		c.processRetry(ctx, key.(string))
+	}
+}
+
+func (c *Client) processRetry(ctx context.Context, key string) {
+	defer c.retries.queue.Done(key)
+	c.retries.mu.Lock()
+	pending, ok := c.retries.pending[key]
+	c.retries.mu.Unlock()
+	if !ok {
+		// superseded by a newer write
+		c.retries.queue.Forget(key)
+		return
+	}
+	ctx = utils.ContextFromGeneric(ctx, domain.Generic{Depth: pending.depth, MsgId: pending.msgId})
+	err := pending.write(ctx)
+	c.retries.mu.Lock()
+	if c.retries.pending[key] != pending {
+		// superseded while retrying
+		c.retries.mu.Unlock()
+		return
+	}
+	attempts := 1 + c.retries.queue.NumRequeues(key)
+	retry := err != nil && isRetriable(err) && attempts < c.retries.maxAttempts
+	if retry {
+		c.retries.queue.AddRateLimited(key)
+	} else {
+		delete(c.retries.pending, key)
+		c.retries.queue.Forget(key)
+	}
+	c.retries.mu.Unlock()
+	// reported outside the lock, which every failing write needs
+	switch {
+	case err == nil:
+		writeRetriesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueSucceeded).Inc()
+		logger.L().Ctx(ctx).Info("write succeeded after retry", helpers.String("id", key), helpers.Int("attempts", attempts))
+	case !isRetriable(err):
+		// reported by the write itself when relevant, e.g. conflicts
+		logger.L().Ctx(ctx).Error("write failed after retry", helpers.Error(err), helpers.String("id", key), helpers.Int("attempts", attempts))
+	case retry:
+		logger.L().Ctx(ctx).Warning("write failed again, retrying later", helpers.Error(err), helpers.String("id", key), helpers.Int("attempts", attempts))
+	default:
+		writeRetriesCounter.WithLabelValues(c.res.Resource, prometheusOutcomeLabelValueExhausted).Inc()
+		logger.L().Ctx(ctx).Error("giving up write", helpers.Error(err), helpers.String("id", key), helpers.Int("attempts", attempts))
+		if reportErr := c.callbacks.WriteFailed(ctx, pending.id, domain.WriteRetriesExhausted, err.Error()); reportErr != nil {
+			logger.L().Ctx(ctx).Error("cannot report failed write", helpers.Error(reportErr), helpers.String("id", key))
+		}
+	}
+}
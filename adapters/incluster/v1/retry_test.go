@@ -0,0 +1,255 @@
+package incluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubescape/synchronizer/config"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/kubescape/synchronizer/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func Test_isRetriable(t *testing.T) {
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "server timeout",
+			err:  apierrors.NewServerTimeout(gr, "apply", 1),
+			want: true,
+		},
+		{
+			name: "timeout",
+			err:  apierrors.NewTimeoutError("webhook timed out", 1),
+			want: true,
+		},
+		{
+			name: "too many requests",
+			err:  apierrors.NewTooManyRequests("slow down", 1),
+			want: true,
+		},
+		{
+			name: "service unavailable",
+			err:  apierrors.NewServiceUnavailable("down"),
+			want: true,
+		},
+		{
+			name: "wrapped internal error",
+			err:  fmt.Errorf("apply resource: %w", apierrors.NewInternalError(errors.New("boom"))),
+			want: true,
+		},
+		{
+			name: "network error",
+			err:  &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want: true,
+		},
+		{
+			name: "conflict",
+			err:  apierrors.NewConflict(gr, "name", errors.New("conflict")),
+		},
+		{
+			name: "forbidden",
+			err:  apierrors.NewForbidden(gr, "name", errors.New("forbidden")),
+		},
+		{
+			name: "invalid object",
+			err:  errors.New("unmarshal object: invalid"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetriable(tt.err))
+		})
+	}
+}
+
+func TestClient_processRetry(t *testing.T) {
+	transient := apierrors.NewServiceUnavailable("down")
+	tests := []struct {
+		name       string
+		failures   int // number of times the retried write fails
+		err        error
+		supersede  bool
+		wantCalls  int
+		wantReason domain.WriteFailureReason
+	}{
+		{
+			name:      "succeeds after retry",
+			failures:  1,
+			err:       transient,
+			wantCalls: 2,
+		},
+		{
+			name:       "retries exhausted",
+			failures:   10,
+			err:        transient,
+			wantCalls:  2, // the failed write itself is the first attempt
+			wantReason: domain.WriteRetriesExhausted,
+		},
+		{
+			name:      "permanent error",
+			failures:  10,
+			err:       apierrors.NewForbidden(schema.GroupResource{Resource: "deployments"}, "name", errors.New("forbidden")),
+			wantCalls: 1,
+		},
+		{
+			name:      "superseded by a newer write",
+			failures:  10,
+			err:       transient,
+			supersede: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason domain.WriteFailureReason
+			c := &Client{
+				res:     schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				retries: newWriteRetries(3, newRetryLimiter()),
+			}
+			c.callbacks = domain.Callbacks{
+				WriteFailed: func(_ context.Context, _ domain.KindName, r domain.WriteFailureReason, _ string) error {
+					// reported without holding the lock of the retries
+					require.True(t, c.retries.mu.TryLock())
+					c.retries.mu.Unlock()
+					reason = r
+					return nil
+				},
+			}
+			c.retries.queue = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond))
+			id := domain.KindName{Kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, Name: "name", Namespace: "ns"}
+			var calls int
+			c.retryOnFailure(context.TODO(), id, transient, func(_ context.Context) error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if tt.supersede {
+				c.cancelRetry(id)
+			}
+			for c.retries.queue.Len() > 0 || len(c.retries.pending) > 0 {
+				key, _ := c.retries.queue.Get()
+				c.processRetry(context.TODO(), key.(string))
+				time.Sleep(5 * time.Millisecond)
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantReason, reason)
+			assert.Empty(t, c.retries.pending)
+		})
+	}
+}
+
+func TestAdapter_retriesBackendToClusterWrites(t *testing.T) {
+	r := config.Resource{Group: "apps", Version: "v1", Resource: "deployments", Strategy: domain.CopyStrategy, Direction: domain.BackendToCluster}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	var patches atomic.Int32
+	client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if patches.Add(1) == 1 {
+			return true, nil, apierrors.NewServiceUnavailable("down")
+		}
+		applied := &unstructured.Unstructured{}
+		err := applied.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch())
+		return true, applied, err
+	})
+	a := NewInClusterAdapter(config.InCluster{Resources: []config.Resource{r}}, client)
+	require.NoError(t, a.Start(context.TODO()))
+	defer func() {
+		_ = a.Stop(context.TODO())
+	}()
+	id := domain.KindName{Kind: &domain.Kind{Group: r.Group, Version: r.Version, Resource: r.Resource}, Name: "name", Namespace: "ns"}
+	object := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"name","namespace":"ns"}}`)
+	ctx := utils.ContextFromGeneric(context.TODO(), domain.Generic{})
+	assert.Error(t, a.PutObject(ctx, id, object))
+	// the failed write is retried by the worker, although the resource is not watched
+	assert.Eventually(t, func() bool {
+		return patches.Load() == 2
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestAdapter_sharesRetryLimiter(t *testing.T) {
+	cfg := config.InCluster{Resources: []config.Resource{
+		{Group: "apps", Version: "v1", Resource: "deployments", Strategy: domain.CopyStrategy, Direction: domain.BackendToCluster},
+		{Group: "apps", Version: "v1", Resource: "statefulsets", Strategy: domain.CopyStrategy, Direction: domain.BackendToCluster},
+	}}
+	a := NewInClusterAdapter(cfg, fake.NewSimpleDynamicClient(runtime.NewScheme()))
+	require.NoError(t, a.Start(context.TODO()))
+	defer func() {
+		_ = a.Stop(context.TODO())
+	}()
+	for _, r := range cfg.Resources {
+		client, ok := a.configuredClient(r)
+		require.True(t, ok)
+		assert.Same(t, a.retryLimiter, client.retries.limiter)
+	}
+}
+
+func TestClient_PatchObject_retryStaleBase(t *testing.T) {
+	res := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	id := domain.KindName{Kind: &domain.Kind{Group: "apps", Version: "v1", Resource: "deployments"}, Name: "test", Namespace: "default"}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+		"spec":       map[string]interface{}{"replicas": int64(1)},
+	}}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), live.DeepCopy())
+	client.PrependReactor("patch", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("down")
+	})
+	var gets int
+	c := &Client{
+		client:        client,
+		kind:          id.Kind,
+		res:           res,
+		direction:     domain.BackendToCluster,
+		Strategy:      domain.PatchStrategy,
+		fieldManager:  config.DefaultFieldManager,
+		ShadowObjects: NewMemoryShadowStore(),
+		retries:       newWriteRetries(3, newRetryLimiter()),
+		callbacks: domain.Callbacks{
+			GetObject: func(context.Context, domain.KindName, []byte) error {
+				gets++
+				return nil
+			},
+		},
+	}
+	c.retries.queue = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond))
+	ctx := utils.ContextFromGeneric(context.TODO(), domain.Generic{})
+	object, err := c.filterAndMarshal(ctx, live.DeepCopy())
+	require.NoError(t, err)
+	patch := []byte(`{"spec":{"replicas":2}}`)
+	modified, err := utils.ApplyPatch(domain.MergePatch, object, patch)
+	require.NoError(t, err)
+	checksum, err := utils.CanonicalHash(modified)
+	require.NoError(t, err)
+	assert.Error(t, c.PatchObject(ctx, id, checksum, domain.MergePatch, patch))
+	assert.Equal(t, 0, gets)
+	// the object changes before the retry, the patch does not match anymore
+	changed := live.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(changed.Object, true, "spec", "paused"))
+	_, err = client.Resource(res).Namespace("default").Update(context.TODO(), changed, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	key, _ := c.retries.queue.Get()
+	c.processRetry(ctx, key.(string))
+	assert.Equal(t, 1, gets)
+	assert.Empty(t, c.retries.pending)
+}
@@ -0,0 +1,254 @@
+package incluster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+)
+
+// ShadowStore keeps the last version of the objects sent to the backend,
+// they are used as a base to calculate patches.
+type ShadowStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, object []byte)
+	Delete(key string)
+}
+
+// MemoryShadowStore is a ShadowStore kept in memory, it is safe for concurrent use.
+type MemoryShadowStore struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+var _ ShadowStore = (*MemoryShadowStore)(nil)
+
+func NewMemoryShadowStore() *MemoryShadowStore {
+	return &MemoryShadowStore{
+		objects: map[string][]byte{},
+	}
+}
+
+func (m *MemoryShadowStore) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	object, ok := m.objects[key]
+	return object, ok
+}
+
+func (m *MemoryShadowStore) Set(key string, object []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = object
+}
+
+func (m *MemoryShadowStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+}
+
+const diskShadowStoreExt = ".gz"
+
+type diskEntry struct {
+	size     int64
+	modified time.Time
+}
+
+// DiskShadowStore is a ShadowStore persisted on disk, so that patches survive restarts.
+// Objects are gzip compressed, one file per object, and the oldest ones are evicted
+// when the total size exceeds maxBytes. It is safe for concurrent use, the lock only
+// guards the entries, files are read and written outside of it.
+type DiskShadowStore struct {
+	mu        sync.Mutex
+	dir       string
+	maxBytes  int64
+	totalSize int64
+	entries   map[string]diskEntry // file name -> entry
+}
+
+var _ ShadowStore = (*DiskShadowStore)(nil)
+
+// NewDiskShadowStore opens or creates a store in dir, maxBytes <= 0 means unbounded.
+func NewDiskShadowStore(dir string, maxBytes int64) (*DiskShadowStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create shadow store directory: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read shadow store directory: %w", err)
+	}
+	d := &DiskShadowStore{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  map[string]diskEntry{},
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "tmp-") {
+			// leftover from an interrupted write
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		if f.IsDir() || !strings.HasSuffix(f.Name(), diskShadowStoreExt) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		d.entries[f.Name()] = diskEntry{size: info.Size(), modified: info.ModTime()}
+		d.totalSize += info.Size()
+	}
+	d.removeFiles(d.evict())
+	return d, nil
+}
+
+// fileName hashes the key, as keys contain slashes and can be longer than allowed file names.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + diskShadowStoreExt
+}
+
+func (d *DiskShadowStore) Get(key string) ([]byte, bool) {
+	name := fileName(key)
+	d.mu.Lock()
+	entry, ok := d.entries[name]
+	d.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	object, err := readShadowFile(filepath.Join(d.dir, name))
+	if err != nil {
+		logger.L().Warning("cannot read shadow object", helpers.Error(err), helpers.String("key", key))
+		d.mu.Lock()
+		// the object might have been written again in the meantime
+		forgotten := d.entries[name] == entry && d.forget(name)
+		d.mu.Unlock()
+		if forgotten {
+			d.removeFiles([]string{name})
+		}
+		return nil, false
+	}
+	return object, true
+}
+
+// readShadowFile reads and decompresses an object.
+func readShadowFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func (d *DiskShadowStore) Set(key string, object []byte) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(object); err != nil {
+		logger.L().Warning("cannot compress shadow object", helpers.Error(err), helpers.String("key", key))
+		return
+	}
+	if err := w.Close(); err != nil {
+		logger.L().Warning("cannot compress shadow object", helpers.Error(err), helpers.String("key", key))
+		return
+	}
+	name := fileName(key)
+	// write to a temporary file first, so that a crash never leaves a truncated object
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		logger.L().Warning("cannot create shadow object", helpers.Error(err), helpers.String("key", key))
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(d.dir, name))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		logger.L().Warning("cannot write shadow object", helpers.Error(err), helpers.String("key", key))
+		return
+	}
+	d.mu.Lock()
+	if old, ok := d.entries[name]; ok {
+		d.totalSize -= old.size
+	}
+	d.entries[name] = diskEntry{size: int64(buf.Len()), modified: time.Now()}
+	d.totalSize += int64(buf.Len())
+	evicted := d.evict()
+	d.mu.Unlock()
+	d.removeFiles(evicted)
+}
+
+func (d *DiskShadowStore) Delete(key string) {
+	name := fileName(key)
+	d.mu.Lock()
+	forgotten := d.forget(name)
+	d.mu.Unlock()
+	if forgotten {
+		d.removeFiles([]string{name})
+	}
+}
+
+// forget drops the entry of a file, the lock must be held.
+func (d *DiskShadowStore) forget(name string) bool {
+	entry, ok := d.entries[name]
+	if !ok {
+		return false
+	}
+	d.totalSize -= entry.size
+	delete(d.entries, name)
+	return true
+}
+
+// removeFiles deletes the files of forgotten entries, the lock must not be held.
+func (d *DiskShadowStore) removeFiles(names []string) {
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !os.IsNotExist(err) {
+			logger.L().Warning("cannot delete shadow object", helpers.Error(err), helpers.String("file", name))
+		}
+	}
+}
+
+// evict forgets the oldest objects until the store is under 90% of its limit, the lock must be held.
+// It returns the files to remove once the lock is released.
+func (d *DiskShadowStore) evict() []string {
+	if d.maxBytes <= 0 || d.totalSize <= d.maxBytes {
+		return nil
+	}
+	names := make([]string, 0, len(d.entries))
+	for name := range d.entries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return d.entries[names[i]].modified.Before(d.entries[names[j]].modified)
+	})
+	target := d.maxBytes * 9 / 10
+	var evicted []string
+	for _, name := range names {
+		if d.totalSize <= target {
+			break
+		}
+		d.forget(name)
+		evicted = append(evicted, name)
+	}
+	return evicted
+}
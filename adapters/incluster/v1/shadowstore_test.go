@@ -0,0 +1,113 @@
+package incluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowStore(t *testing.T) {
+	disk, err := NewDiskShadowStore(t.TempDir(), 0)
+	require.NoError(t, err)
+	tests := []struct {
+		name  string
+		store ShadowStore
+	}{
+		{
+			name:  "memory",
+			store: NewMemoryShadowStore(),
+		},
+		{
+			name:  "disk",
+			store: disk,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := "apps/v1/deployments/default/nginx"
+			_, ok := tt.store.Get(key)
+			assert.False(t, ok)
+			tt.store.Set(key, []byte(`{"kind":"Deployment"}`))
+			object, ok := tt.store.Get(key)
+			assert.True(t, ok)
+			assert.Equal(t, []byte(`{"kind":"Deployment"}`), object)
+			tt.store.Set(key, []byte(`{"kind":"Deployment","spec":{}}`))
+			object, ok = tt.store.Get(key)
+			assert.True(t, ok)
+			assert.Equal(t, []byte(`{"kind":"Deployment","spec":{}}`), object)
+			tt.store.Delete(key)
+			_, ok = tt.store.Get(key)
+			assert.False(t, ok)
+			// concurrent access
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					k := fmt.Sprintf("%s-%d", key, i)
+					tt.store.Set(k, []byte(k))
+					object, ok := tt.store.Get(k)
+					assert.True(t, ok)
+					assert.Equal(t, []byte(k), object)
+					tt.store.Delete(k)
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestDiskShadowStore_persistence(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDiskShadowStore(dir, 0)
+	require.NoError(t, err)
+	store.Set("a", []byte("object a"))
+	// reopen the store, as after a restart
+	store, err = NewDiskShadowStore(dir, 0)
+	require.NoError(t, err)
+	object, ok := store.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("object a"), object)
+}
+
+func TestDiskShadowStore_eviction(t *testing.T) {
+	store, err := NewDiskShadowStore(t.TempDir(), 1)
+	require.NoError(t, err)
+	store.Set("a", []byte("object a"))
+	_, ok := store.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), store.totalSize)
+	// unbounded store keeps everything
+	store.maxBytes = 0
+	store.Set("a", []byte("object a"))
+	store.Set("b", []byte("object b"))
+	_, ok = store.Get("a")
+	assert.True(t, ok)
+	// limit between one and two objects, the oldest is evicted
+	store.maxBytes = store.totalSize * 3 / 4
+	store.Set("b", []byte("object b"))
+	_, ok = store.Get("a")
+	assert.False(t, ok)
+	_, ok = store.Get("b")
+	assert.True(t, ok)
+}
+
+func TestDiskShadowStore_unreadable(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDiskShadowStore(dir, 0)
+	require.NoError(t, err)
+	store.Set("a", []byte("object a"))
+	// corrupt the file, the entry is dropped when read
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fileName("a")), []byte("not gzip"), 0o600))
+	_, ok := store.Get("a")
+	assert.False(t, ok)
+	assert.Empty(t, store.entries)
+	assert.Equal(t, int64(0), store.totalSize)
+	_, err = os.Stat(filepath.Join(dir, fileName("a")))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,51 @@
+package incluster
+
+import (
+	"context"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/kubescape/synchronizer/utils"
+)
+
+// alwaysIgnoredFields change with every update, on top of the fields ignored by utils.CanonicalHash.
+var alwaysIgnoredFields = []string{".metadata.resourceVersion"}
+
+// sentChecksum returns the checksum used to detect changes worth sending, ignoring the configured fields.
+func (c *Client) sentChecksum(object []byte) (string, error) {
+	return utils.CanonicalHashExcluding(object, c.ignoredFields...)
+}
+
+// isUnchanged returns true if the copy strategy already sent the object with the same content,
+// modified events are then suppressed.
+func (c *Client) isUnchanged(ctx context.Context, id domain.KindName, object []byte) bool {
+	if c.Strategy.Patches() {
+		return false
+	}
+	last, ok := c.lastSent.Load(id.String())
+	if !ok {
+		return false
+	}
+	checksum, err := c.sentChecksum(object)
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("cannot calculate checksum, sending object", helpers.Error(err), helpers.String("id", id.String()))
+		return false
+	}
+	return last == checksum
+}
+
+// markSent remembers the checksum of the object known by the backend.
+func (c *Client) markSent(id domain.KindName, object []byte) {
+	if c.Strategy.Patches() {
+		return
+	}
+	if checksum, err := c.sentChecksum(object); err == nil {
+		c.lastSent.Store(id.String(), checksum)
+	}
+}
+
+// forgetSent drops the checksum of an object the backend does not know anymore.
+func (c *Client) forgetSent(id domain.KindName) {
+	c.lastSent.Delete(id.String())
+}
@@ -0,0 +1,72 @@
+package incluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_isUnchanged(t *testing.T) {
+	sent := []byte(`{"metadata":{"name":"node","resourceVersion":"1"},"spec":{"a":"b"},"status":{"lastHeartbeatTime":"1"}}`)
+	tests := []struct {
+		name          string
+		strategy      domain.Strategy
+		ignoredFields []string
+		notSent       bool
+		object        []byte
+		want          bool
+	}{
+		{
+			name:     "only resourceVersion changed",
+			strategy: domain.CopyStrategy,
+			object:   []byte(`{"metadata":{"name":"node","resourceVersion":"2"},"spec":{"a":"b"},"status":{"lastHeartbeatTime":"1"}}`),
+			want:     true,
+		},
+		{
+			name:          "only ignored field changed",
+			strategy:      domain.CopyStrategy,
+			ignoredFields: []string{".status.lastHeartbeatTime"},
+			object:        []byte(`{"metadata":{"name":"node","resourceVersion":"2"},"spec":{"a":"b"},"status":{"lastHeartbeatTime":"2"}}`),
+			want:          true,
+		},
+		{
+			name:     "status changed",
+			strategy: domain.CopyStrategy,
+			object:   []byte(`{"metadata":{"name":"node","resourceVersion":"2"},"spec":{"a":"b"},"status":{"lastHeartbeatTime":"2"}}`),
+		},
+		{
+			name:          "spec changed",
+			strategy:      domain.CopyStrategy,
+			ignoredFields: []string{".status.lastHeartbeatTime"},
+			object:        []byte(`{"metadata":{"name":"node","resourceVersion":"2"},"spec":{"a":"c"},"status":{"lastHeartbeatTime":"2"}}`),
+		},
+		{
+			name:     "never sent",
+			strategy: domain.CopyStrategy,
+			notSent:  true,
+			object:   sent,
+		},
+		{
+			name:     "patch strategy",
+			strategy: domain.PatchStrategy,
+			object:   sent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				Strategy:      tt.strategy,
+				ignoredFields: append(append([]string{}, alwaysIgnoredFields...), tt.ignoredFields...),
+			}
+			id := domain.KindName{Kind: &domain.Kind{Version: "v1", Resource: "nodes"}, Name: "node"}
+			if !tt.notSent {
+				c.markSent(id, sent)
+			}
+			assert.Equal(t, tt.want, c.isUnchanged(context.TODO(), id, tt.object))
+			c.forgetSent(id)
+			assert.False(t, c.isUnchanged(context.TODO(), id, tt.object))
+		})
+	}
+}
@@ -14,10 +14,13 @@ type Adapter interface {
 	Callbacks(ctx context.Context) (domain.Callbacks, error) // returns the callbacks for the given context
 	DeleteObject(ctx context.Context, id domain.KindName) error
 	GetObject(ctx context.Context, id domain.KindName, baseObject []byte) error
-	PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error
+	PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error
 	PutObject(ctx context.Context, id domain.KindName, object []byte) error
 	VerifyObject(ctx context.Context, id domain.KindName, checksum string) error
 	Batch(ctx context.Context, id domain.Kind, batchType domain.BatchType, items domain.BatchItems) error
+	WriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error
+	ResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error
+	PermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error
 }
 
 type Client Adapter
@@ -3,9 +3,9 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/armosec/utils-k8s-go/armometadata"
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/synchronizer/domain"
@@ -15,8 +15,9 @@ import (
 
 type MockAdapter struct {
 	callbacks            domain.Callbacks
-	checkResourceVersion bool // false for client, true for server
-	patchStrategy        bool // true for client, false for server
+	checkResourceVersion bool             // false for client, true for server
+	patchStrategy        bool             // true for client, false for server
+	PatchType            domain.PatchType // type of patches sent with the patch strategy
 	Resources            map[string][]byte
 	shadowObjects        map[string][]byte
 }
@@ -25,6 +26,7 @@ func NewMockAdapter(isClient bool) *MockAdapter {
 	return &MockAdapter{
 		checkResourceVersion: !isClient,
 		patchStrategy:        isClient,
+		PatchType:            domain.MergePatch,
 		Resources:            map[string][]byte{},
 		shadowObjects:        map[string][]byte{},
 	}
@@ -40,6 +42,7 @@ func (m *MockAdapter) Batch(ctx context.Context, kind domain.Kind, _ domain.Batc
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, m.GetObject(ctx, id, []byte(item.BaseObject)))
 	}
@@ -50,6 +53,7 @@ func (m *MockAdapter) Batch(ctx context.Context, kind domain.Kind, _ domain.Batc
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, m.VerifyObject(ctx, id, item.Checksum))
 	}
@@ -60,6 +64,7 @@ func (m *MockAdapter) Batch(ctx context.Context, kind domain.Kind, _ domain.Batc
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, m.DeleteObject(ctx, id))
 	}
@@ -70,8 +75,9 @@ func (m *MockAdapter) Batch(ctx context.Context, kind domain.Kind, _ domain.Batc
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
-		err = multierr.Append(err, m.PatchObject(ctx, id, item.Checksum, []byte(item.Patch)))
+		err = multierr.Append(err, m.PatchObject(ctx, id, item.Checksum, item.PatchType, []byte(item.Patch)))
 	}
 
 	for _, item := range items.PutObject {
@@ -80,6 +86,7 @@ func (m *MockAdapter) Batch(ctx context.Context, kind domain.Kind, _ domain.Batc
 			Name:            item.Name,
 			Namespace:       item.Namespace,
 			ResourceVersion: item.ResourceVersion,
+			UID:             item.UID,
 		}
 		err = multierr.Append(err, m.PutObject(ctx, id, []byte(item.Object)))
 	}
@@ -116,11 +123,11 @@ func (m *MockAdapter) GetObject(ctx context.Context, id domain.KindName, baseObj
 				return fmt.Errorf("calculate checksum: %w", err)
 			}
 			// calculate patch
-			patch, err := jsonpatch.CreateMergePatch(oldObject, object)
+			patch, err := utils.CreatePatch(m.PatchType, oldObject, object)
 			if err != nil {
-				return fmt.Errorf("create merge patch: %w", err)
+				return fmt.Errorf("create patch: %w", err)
 			}
-			return m.callbacks.PatchObject(ctx, id, checksum, patch)
+			return m.callbacks.PatchObject(ctx, id, checksum, m.PatchType, patch)
 		} else {
 			return m.callbacks.PutObject(ctx, id, object)
 		}
@@ -129,8 +136,8 @@ func (m *MockAdapter) GetObject(ctx context.Context, id domain.KindName, baseObj
 	}
 }
 
-func (m *MockAdapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
-	baseObject, err := m.patchObject(id, checksum, patch)
+func (m *MockAdapter) PatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
+	baseObject, err := m.patchObject(id, checksum, patchType, patch)
 	if err != nil {
 		logger.L().Ctx(ctx).Warning("patch object, sending get object", helpers.Error(err), helpers.String("id", id.String()))
 		return m.callbacks.GetObject(ctx, id, baseObject)
@@ -138,12 +145,12 @@ func (m *MockAdapter) PatchObject(ctx context.Context, id domain.KindName, check
 	return nil
 }
 
-func (m *MockAdapter) patchObject(id domain.KindName, checksum string, patch []byte) ([]byte, error) {
+func (m *MockAdapter) patchObject(id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) ([]byte, error) {
 	object, ok := m.Resources[id.String()]
 	if !ok {
 		return nil, fmt.Errorf("object not found")
 	}
-	modified, err := jsonpatch.MergePatch(object, patch)
+	modified, err := utils.ApplyPatch(patchType, object, patch)
 	if err != nil {
 		return object, fmt.Errorf("apply patch: %w", err)
 	}
@@ -204,6 +211,21 @@ func (m *MockAdapter) VerifyObject(ctx context.Context, id domain.KindName, newC
 	return nil
 }
 
+func (m *MockAdapter) WriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	logger.L().Ctx(ctx).Warning("write failed", helpers.String("id", id.String()), helpers.String("reason", string(reason)), helpers.String("message", message))
+	return nil
+}
+
+func (m *MockAdapter) ResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	logger.L().Ctx(ctx).Info("resource status", helpers.String("kind", kind.String()), helpers.String("state", string(state)), helpers.String("message", message))
+	return nil
+}
+
+func (m *MockAdapter) PermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	logger.L().Ctx(ctx).Info("permission report", helpers.String("kind", kind.String()), helpers.String("missing", strings.Join(missing, ", ")), helpers.String("message", message))
+	return nil
+}
+
 func (m *MockAdapter) verifyObject(id domain.KindName, newChecksum string) ([]byte, error) {
 	object, ok := m.Resources[id.String()]
 	if !ok {
@@ -254,11 +276,11 @@ func (m *MockAdapter) TestCallPutOrPatch(ctx context.Context, id domain.KindName
 				return fmt.Errorf("calculate checksum: %w", err)
 			}
 			// calculate patch
-			patch, err := jsonpatch.CreateMergePatch(oldObject, newObject)
+			patch, err := utils.CreatePatch(m.PatchType, oldObject, newObject)
 			if err != nil {
-				return fmt.Errorf("create merge patch: %w", err)
+				return fmt.Errorf("create patch: %w", err)
 			}
-			err = m.callbacks.PatchObject(ctx, id, checksum, patch)
+			err = m.callbacks.PatchObject(ctx, id, checksum, m.PatchType, patch)
 			if err != nil {
 				return fmt.Errorf("send patch object: %w", err)
 			}
@@ -109,7 +109,7 @@ func TestCallPutOrPatch(t *testing.T) {
 			putIDs := []domain.KindName{}
 			m := &MockAdapter{
 				callbacks: domain.Callbacks{
-					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, _ domain.PatchType, patch []byte) error {
 						checksums = append(checksums, checksum)
 						patches = append(patches, patch)
 						patchedIDs = append(patchedIDs, id)
@@ -275,7 +275,7 @@ func TestMockAdapter_GetObject(t *testing.T) {
 			putObjects := [][]byte{}
 			m := &MockAdapter{
 				callbacks: domain.Callbacks{
-					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, _ domain.PatchType, patch []byte) error {
 						checksums = append(checksums, checksum)
 						patches = append(patches, patch)
 						patchedIDs = append(patchedIDs, id)
@@ -383,7 +383,7 @@ func TestMockAdapter_PatchObject(t *testing.T) {
 				Resources:            tt.resources,
 				shadowObjects:        map[string][]byte{},
 			}
-			if err := m.PatchObject(ctx, tt.id, tt.newChecksum, tt.patch); (err != nil) != tt.wantErr {
+			if err := m.PatchObject(ctx, tt.id, tt.newChecksum, domain.MergePatch, tt.patch); (err != nil) != tt.wantErr {
 				t.Errorf("PatchObject() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			assert.Equal(t, tt.wantResources, m.Resources)
@@ -641,7 +641,7 @@ func TestMockAdapter_Batch(t *testing.T) {
 						gotIDs = append(gotIDs, id)
 						return nil
 					},
-					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, patchObj []byte) error {
+					PatchObject: func(ctx context.Context, id domain.KindName, checksum string, _ domain.PatchType, patchObj []byte) error {
 						checksums = append(checksums, checksum)
 						operations = append(operations, patch)
 						gotIDs = append(gotIDs, id)
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// runWithLeaderElection calls run once this replica holds the Lease, and exits when the leadership is lost
+// so that the websocket is closed and the replica restarts as a standby.
+func runWithLeaderElection(ctx context.Context, clientset kubernetes.Interface, cfg config.LeaderElection, run func(ctx context.Context)) {
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      cfg.LeaseName,
+			Namespace: cfg.Namespace,
+		},
+		Client: clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	logger.L().Info("waiting for leadership",
+		helpers.String("lease", cfg.LeaseName),
+		helpers.String("namespace", cfg.Namespace),
+		helpers.String("identity", identity))
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   time.Duration(cfg.LeaseDurationSeconds) * time.Second,
+		RenewDeadline:   time.Duration(cfg.RenewDeadlineSeconds) * time.Second,
+		RetryPeriod:     time.Duration(cfg.RetryPeriodSeconds) * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.L().Info("leadership acquired, connecting to the backend", helpers.String("identity", identity))
+				run(ctx)
+			},
+			OnStoppedLeading: func() {
+				logger.L().Fatal("leadership lost, exiting", helpers.String("identity", identity))
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					logger.L().Info("standing by", helpers.String("leader", leader))
+				}
+			},
+		},
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/kubescape/synchronizer/core"
 	"github.com/kubescape/synchronizer/domain"
 	"github.com/kubescape/synchronizer/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -75,13 +76,34 @@ func main() {
 		Cluster: cfg.InCluster.ClusterName,
 	})
 
+	// enable prometheus metrics
+	if cfg.InCluster.Prometheus != nil && cfg.InCluster.Prometheus.Enabled {
+		go func() {
+			logger.L().Info("prometheus metrics enabled", helpers.Int("port", cfg.InCluster.Prometheus.Port))
+			http.Handle("/metrics", promhttp.Handler())
+			_ = http.ListenAndServe(fmt.Sprintf(":%d", cfg.InCluster.Prometheus.Port), nil)
+		}()
+	}
+
 	// k8s client
-	k8sclient, err := utils.NewClient()
+	var clientCfg config.KubernetesClient
+	if cfg.InCluster.KubernetesClient != nil {
+		clientCfg = *cfg.InCluster.KubernetesClient
+	}
+	k8sclient, err := utils.NewClient(clientCfg)
 	if err != nil {
 		logger.L().Fatal("unable to create k8s client", helpers.Error(err))
 	}
+	clientset, err := utils.NewClientset()
+	if err != nil {
+		logger.L().Fatal("unable to create k8s clientset", helpers.Error(err))
+	}
 	// in-cluster adapter
 	adapter := incluster.NewInClusterAdapter(cfg.InCluster, k8sclient)
+	adapter.SetRESTMapper(utils.NewRESTMapper(clientset))
+
+	// RBAC pre-flight check, resources missing permissions are skipped and reported to the backend
+	adapter.SkipUnauthorized(checkPermissions(ctx, clientset, cfg.InCluster))
 
 	// authentication headers
 	version := os.Getenv("RELEASE")
@@ -100,7 +122,9 @@ func main() {
 	utils.ServePprof()
 
 	// start liveness probe
-	utils.StartLivenessProbe()
+	utils.StartLivenessProbe(func() any {
+		return adapter.Health()
+	})
 
 	// websocket client
 	newConn := func() (net.Conn, error) {
@@ -121,18 +145,31 @@ func main() {
 		return conn, nil
 	}
 
-	conn, err := newConn()
-	if err != nil {
-		logger.L().Ctx(ctx).Fatal("failed to connect", helpers.Error(err))
+	// connect to the backend and synchronize
+	run := func(ctx context.Context) {
+		conn, err := newConn()
+		if err != nil {
+			logger.L().Ctx(ctx).Fatal("failed to connect", helpers.Error(err))
+		}
+
+		// synchronizer
+		synchronizer, err := core.NewSynchronizerClient(ctx, adapter, conn, newConn)
+		if err != nil {
+			logger.L().Ctx(ctx).Fatal("failed to create synchronizer", helpers.Error(err))
+		}
+		err = synchronizer.Start(ctx)
+		if err != nil {
+			logger.L().Ctx(ctx).Fatal("error during sync, exiting", helpers.Error(err))
+		}
 	}
 
-	// synchronizer
-	synchronizer, err := core.NewSynchronizerClient(ctx, adapter, conn, newConn)
-	if err != nil {
-		logger.L().Ctx(ctx).Fatal("failed to create synchronizer", helpers.Error(err))
+	if cfg.InCluster.LeaderElection == nil || !cfg.InCluster.LeaderElection.Enabled {
+		run(ctx)
+		return
 	}
-	err = synchronizer.Start(ctx)
-	if err != nil {
-		logger.L().Ctx(ctx).Fatal("error during sync, exiting", helpers.Error(err))
+	// stay warm while standing by: watch the cluster without sending anything
+	if err := adapter.Start(ctx); err != nil {
+		logger.L().Ctx(ctx).Fatal("failed to start adapter", helpers.Error(err))
 	}
+	runWithLeaderElection(ctx, clientset, cfg.InCluster.LeaderElection.WithDefaults(), run)
 }
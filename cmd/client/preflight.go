@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/adapters/incluster/v1"
+	"github.com/kubescape/synchronizer/config"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkPermissions reviews the RBAC permissions of the configured resources and logs a report.
+func checkPermissions(ctx context.Context, clientset kubernetes.Interface, cfg config.InCluster) []incluster.PermissionReport {
+	reports := incluster.CheckPermissions(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews(), cfg)
+	var skipped int
+	for _, report := range reports {
+		switch {
+		case !report.Allowed():
+			skipped++
+			logger.L().Error("RBAC pre-flight check: skipping resource", helpers.String("resource", report.Resource.String()),
+				helpers.String("direction", string(report.Resource.Direction)), helpers.String("reason", report.String()))
+		case report.Err != nil:
+			logger.L().Warning("RBAC pre-flight check: cannot review permissions, assuming they are granted", helpers.Error(report.Err),
+				helpers.String("resource", report.Resource.String()))
+		default:
+			logger.L().Debug("RBAC pre-flight check: resource allowed", helpers.String("resource", report.Resource.String()))
+		}
+	}
+	logger.L().Info("RBAC pre-flight check done", helpers.Int("resources", len(reports)), helpers.Int("skipped", skipped))
+	return reports
+}
@@ -76,7 +76,7 @@ func main() {
 	utils.ServePprof()
 
 	// start liveness probe
-	utils.StartLivenessProbe()
+	utils.StartLivenessProbe(nil)
 
 	var addr string
 	if cfg.Backend.Port > 0 {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/armosec/utils-k8s-go/armometadata"
@@ -9,6 +10,7 @@ import (
 	"github.com/kubescape/backend/pkg/servicediscovery/schema"
 	v2 "github.com/kubescape/backend/pkg/servicediscovery/v2"
 	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
 	pulsarconfig "github.com/kubescape/messaging/pulsar/config"
 	pulsarconnector "github.com/kubescape/messaging/pulsar/connector"
 	"github.com/kubescape/synchronizer/domain"
@@ -32,18 +34,216 @@ type Backend struct {
 }
 
 type InCluster struct {
-	ServerUrl   string     `mapstructure:"serverUrl"`
-	ClusterName string     `mapstructure:"clusterName"`
-	Account     string     `mapstructure:"account"`
-	AccessKey   string     `mapstructure:"accessKey"`
-	Resources   []Resource `mapstructure:"resources"`
+	ServerUrl    string            `mapstructure:"serverUrl"`
+	ClusterName  string            `mapstructure:"clusterName"`
+	Account      string            `mapstructure:"account"`
+	AccessKey    string            `mapstructure:"accessKey"`
+	FieldManager string            `mapstructure:"fieldManager"` // field manager used for server-side apply, defaults to DefaultFieldManager
+	DryRun       bool              `mapstructure:"dryRun"`       // writes from the backend are only simulated for all resources
+	Resources    []Resource        `mapstructure:"resources"`
+	Prometheus   *PrometheusConfig `mapstructure:"prometheusConfig"`
+	ShadowStore  *ShadowStore      `mapstructure:"shadowStore"` // nil keeps shadow objects in memory
+	// LeaderElection allows running several replicas, only the leader connects to the backend
+	LeaderElection *LeaderElection `mapstructure:"leaderElection"`
+	// ListPageSize is the number of objects fetched per page when listing resources, defaults to DefaultListPageSize
+	ListPageSize int `mapstructure:"listPageSize"`
+	// ReconciliationIntervalSeconds is how often the inventory of all watched resources is sent to the backend, 0 disables it
+	ReconciliationIntervalSeconds int `mapstructure:"reconciliationIntervalSeconds"`
+	// CreateNamespaces creates the missing namespaces of objects written from the backend in batches,
+	// namespaces must also be configured as a writable resource
+	CreateNamespaces bool `mapstructure:"createNamespaces"`
+	// Events records Kubernetes Events for the changes made from the backend, nil disables them
+	Events *Events `mapstructure:"events"`
+	// KubernetesClient configures the client used to sync the resources, nil uses the defaults below
+	KubernetesClient *KubernetesClient `mapstructure:"kubernetesClient"`
 }
 
+// KubernetesClient configures the client used to sync the resources. Its rate limiter is shared by all resources,
+// the client used for leader election is not affected so that lease renewals are never throttled.
+type KubernetesClient struct {
+	// QPS is the sustained rate of requests to the API server, 0 means DefaultClientQPS
+	QPS float32 `mapstructure:"qps"`
+	// Burst is the maximum number of requests sent at once, 0 means DefaultClientBurst
+	Burst int `mapstructure:"burst"`
+	// TimeoutSeconds bounds each request, including watches which are then resumed, 0 disables it
+	TimeoutSeconds int `mapstructure:"timeoutSeconds"`
+}
+
+//goland:noinspection GoUnusedConst
+const (
+	DefaultClientQPS   = 50
+	DefaultClientBurst = 100
+)
+
+// GetQPS returns the configured QPS, or the default one if not set.
+func (k KubernetesClient) GetQPS() float32 {
+	if k.QPS <= 0 {
+		return DefaultClientQPS
+	}
+	return k.QPS
+}
+
+// GetBurst returns the configured burst, or the default one if not set.
+func (k KubernetesClient) GetBurst() int {
+	if k.Burst <= 0 {
+		return DefaultClientBurst
+	}
+	return k.Burst
+}
+
+// Events configures the Kubernetes Events recorded for the changes made from the backend.
+type Events struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinIntervalSeconds is the minimum time between two events for the same object, 0 means DefaultEventMinIntervalSeconds
+	MinIntervalSeconds int `mapstructure:"minIntervalSeconds"`
+}
+
+// DefaultEventMinIntervalSeconds rate limits the events recorded for the same object.
+const DefaultEventMinIntervalSeconds = 30
+
+// GetMinIntervalSeconds returns the configured minimum interval between events, or the default one if not set.
+func (e Events) GetMinIntervalSeconds() int {
+	if e.MinIntervalSeconds <= 0 {
+		return DefaultEventMinIntervalSeconds
+	}
+	return e.MinIntervalSeconds
+}
+
+// LeaderElection configures the Lease used to elect the replica connected to the backend.
+// Zero values use the defaults below.
+type LeaderElection struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	LeaseName            string `mapstructure:"leaseName"`
+	Namespace            string `mapstructure:"namespace"` // defaults to the POD_NAMESPACE environment variable
+	LeaseDurationSeconds int    `mapstructure:"leaseDurationSeconds"`
+	RenewDeadlineSeconds int    `mapstructure:"renewDeadlineSeconds"`
+	RetryPeriodSeconds   int    `mapstructure:"retryPeriodSeconds"`
+}
+
+//goland:noinspection GoUnusedConst
+const (
+	DefaultLeaseName            = "synchronizer"
+	DefaultLeaseNamespace       = "kubescape"
+	DefaultLeaseDurationSeconds = 15
+	DefaultRenewDeadlineSeconds = 10
+	DefaultRetryPeriodSeconds   = 2
+)
+
+// WithDefaults returns a copy of the leader election settings with zero values replaced by the defaults.
+func (l LeaderElection) WithDefaults() LeaderElection {
+	if l.LeaseName == "" {
+		l.LeaseName = DefaultLeaseName
+	}
+	if l.Namespace == "" {
+		l.Namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if l.Namespace == "" {
+		l.Namespace = DefaultLeaseNamespace
+	}
+	if l.LeaseDurationSeconds == 0 {
+		l.LeaseDurationSeconds = DefaultLeaseDurationSeconds
+	}
+	if l.RenewDeadlineSeconds == 0 {
+		l.RenewDeadlineSeconds = DefaultRenewDeadlineSeconds
+	}
+	if l.RetryPeriodSeconds == 0 {
+		l.RetryPeriodSeconds = DefaultRetryPeriodSeconds
+	}
+	return l
+}
+
+// ShadowStore configures where the objects used to calculate patches are kept.
+type ShadowStore struct {
+	Path      string `mapstructure:"path"`      // directory of the on-disk store, empty keeps them in memory
+	MaxSizeMB int    `mapstructure:"maxSizeMB"` // size limit of the on-disk store, 0 means DefaultShadowStoreMaxSizeMB, negative means unbounded
+}
+
+// DefaultFieldManager is the field manager used to apply objects written from the backend.
+const DefaultFieldManager = "application/apply-patch"
+
+// DefaultListPageSize is the number of objects fetched per page when listing resources.
+const DefaultListPageSize = 500
+
+// StorageGroup is the API group of the kubescape storage, whose lists return objects without their spec.
+const StorageGroup = "spdx.softwarecomposition.kubescape.io"
+
+// DefaultShadowStoreMaxSizeMB is the size limit of the on-disk shadow store.
+const DefaultShadowStoreMaxSizeMB = 100
+
+// DefaultMaxWriteAttempts is how many times a write from the backend failing with transient errors is tried.
+const DefaultMaxWriteAttempts = 5
+
+//goland:noinspection GoUnusedConst
+const (
+	DefaultFetchConcurrency = 4  // objects fetched in parallel in list-then-get mode
+	DefaultFetchQPS         = 20 // objects fetched per second in list-then-get mode
+)
+
 type Resource struct {
-	Group    string          `mapstructure:"group"`
-	Version  string          `mapstructure:"version"`
-	Resource string          `mapstructure:"resource"`
-	Strategy domain.Strategy `mapstructure:"strategy"`
+	Group     string           `mapstructure:"group"`
+	Version   string           `mapstructure:"version"`
+	Resource  string           `mapstructure:"resource"`
+	Strategy  domain.Strategy  `mapstructure:"strategy"`
+	Direction domain.Direction `mapstructure:"direction"` // empty means clusterToBackend, writes from the backend are refused
+	Ownership *OwnershipRules  `mapstructure:"ownership"` // nil means DefaultOwnershipRules
+	// ConflictPolicy tells how to handle apply conflicts with other controllers, empty means report
+	ConflictPolicy domain.ConflictPolicy `mapstructure:"conflictPolicy"`
+	// RecreateOnImmutable deletes and recreates objects when an apply fails because of immutable fields
+	RecreateOnImmutable bool `mapstructure:"recreateOnImmutable"`
+	// DryRun only simulates writes from the backend and logs what would change
+	DryRun bool `mapstructure:"dryRun"`
+	// SyncStatus also applies the status subresource of objects written from the backend
+	SyncStatus bool `mapstructure:"syncStatus"`
+	// ReconciliationMode tells how objects are compared during reconciliation, empty means hybrid
+	ReconciliationMode domain.ReconciliationMode `mapstructure:"reconciliationMode"`
+	// CooldownSeconds is how long changes to an object are coalesced before being sent, 0 means utils.DefaultCooldown
+	CooldownSeconds int `mapstructure:"cooldownSeconds"`
+	// MaxDelaySeconds forces sending objects that keep changing during the cooldown, 0 means utils.DefaultMaxDelay
+	MaxDelaySeconds int `mapstructure:"maxDelaySeconds"`
+	// ListThenGet fetches each listed or watched object with a Get, for aggregated APIs returning partial objects.
+	// nil means true for the kubescape storage group (StorageGroup) only, as before it was configurable.
+	ListThenGet *bool `mapstructure:"listThenGet"`
+	// FetchConcurrency is the number of objects fetched in parallel in list-then-get mode, 0 means DefaultFetchConcurrency
+	FetchConcurrency int `mapstructure:"fetchConcurrency"`
+	// FetchQPS limits the objects fetched per second in list-then-get mode, 0 means DefaultFetchQPS
+	FetchQPS float64 `mapstructure:"fetchQPS"`
+	// MaxObjectSizeBytes is the largest marshalled object sent to the backend, 0 means unlimited
+	MaxObjectSizeBytes int `mapstructure:"maxObjectSizeBytes"`
+	// OversizePolicy tells what to do with objects larger than MaxObjectSizeBytes, empty means skip
+	OversizePolicy domain.OversizePolicy `mapstructure:"oversizePolicy"`
+	// Prune deletes objects created from the backend when the backend no longer lists them during reconciliation
+	Prune bool `mapstructure:"prune"`
+	// IgnoredFields are the fields whose changes alone do not trigger a sync with the copy strategy, e.g. ".status.lastHeartbeatTime".
+	// The backend then keeps an older resourceVersion of the object until another field changes.
+	IgnoredFields []string `mapstructure:"ignoredFields"`
+	// MaxWriteAttempts is how many times a write from the backend failing with transient errors is tried, 0 means DefaultMaxWriteAttempts
+	MaxWriteAttempts int `mapstructure:"maxWriteAttempts"`
+}
+
+// OwnershipRules decide which objects are children of another workload.
+// Children are not sent on their own, as their state is already part of their owner.
+type OwnershipRules struct {
+	Kinds           []string     `mapstructure:"kinds"`           // kinds of objects that can be children, e.g. Pod
+	Owners          []OwnerMatch `mapstructure:"owners"`          // owners that make an object a child
+	PodTemplateHash bool         `mapstructure:"podTemplateHash"` // pods with a pod-template-hash label are children
+	SendChildren    bool         `mapstructure:"sendChildren"`    // send children with a reference to their top-level owner instead of dropping them
+}
+
+// OwnerMatch matches an owner reference, empty fields match anything.
+type OwnerMatch struct {
+	APIVersion string `mapstructure:"apiVersion"`
+	Kind       string `mapstructure:"kind"`
+}
+
+// DefaultOwnershipRules skip pods, jobs and replicasets owned by built-in controllers.
+var DefaultOwnershipRules = OwnershipRules{
+	Kinds: []string{"Pod", "Job", "ReplicaSet"},
+	Owners: []OwnerMatch{
+		{APIVersion: "apps/v1"},
+		{APIVersion: "batch/v1"},
+		{APIVersion: "batch/v1beta1"},
+	},
+	PodTemplateHash: true,
 }
 
 type AuthenticationServerConfig struct {
@@ -67,6 +267,75 @@ func (r Resource) String() string {
 	return strings.Join([]string{r.Group, r.Version, r.Resource}, "/")
 }
 
+// GetFieldManager returns the configured field manager, or the default one if not set.
+func (c InCluster) GetFieldManager() string {
+	if c.FieldManager == "" {
+		return DefaultFieldManager
+	}
+	return c.FieldManager
+}
+
+// GetListPageSize returns the configured list page size, or the default one if not set.
+func (c InCluster) GetListPageSize() int {
+	if c.ListPageSize <= 0 {
+		return DefaultListPageSize
+	}
+	return c.ListPageSize
+}
+
+// GetMaxSizeMB returns the configured size limit, or the default one if not set, a negative value means unbounded.
+func (s ShadowStore) GetMaxSizeMB() int {
+	if s.MaxSizeMB == 0 {
+		return DefaultShadowStoreMaxSizeMB
+	}
+	return s.MaxSizeMB
+}
+
+// GetFetchConcurrency returns the configured fetch concurrency, or the default one if not set.
+func (r Resource) GetFetchConcurrency() int {
+	if r.FetchConcurrency <= 0 {
+		return DefaultFetchConcurrency
+	}
+	return r.FetchConcurrency
+}
+
+// GetFetchQPS returns the configured fetch rate, or the default one if not set.
+func (r Resource) GetFetchQPS() float64 {
+	if r.FetchQPS <= 0 {
+		return DefaultFetchQPS
+	}
+	return r.FetchQPS
+}
+
+// GetListThenGet returns whether listed objects are fetched one by one, by default only for the kubescape storage.
+func (r Resource) GetListThenGet() bool {
+	if r.ListThenGet == nil {
+		return r.Group == StorageGroup
+	}
+	return *r.ListThenGet
+}
+
+// GetMaxWriteAttempts returns the configured maximum write attempts, or the default one if not set.
+func (r Resource) GetMaxWriteAttempts() int {
+	if r.MaxWriteAttempts <= 0 {
+		return DefaultMaxWriteAttempts
+	}
+	return r.MaxWriteAttempts
+}
+
+// OwnershipRules returns the ownership rules of the resource, or the default ones if not set.
+func (r Resource) OwnershipRules() OwnershipRules {
+	if r.Ownership == nil {
+		return DefaultOwnershipRules
+	}
+	return *r.Ownership
+}
+
+// Matches returns true if the owner reference matches.
+func (o OwnerMatch) Matches(apiVersion, kind string) bool {
+	return (o.APIVersion == "" || o.APIVersion == apiVersion) && (o.Kind == "" || o.Kind == kind)
+}
+
 // LoadConfig reads configuration from file or environment variables.
 func LoadConfig(path string) (Config, error) {
 	v := viper.New() // singleton prevents running tests in parallel
@@ -129,4 +398,32 @@ func (c *InCluster) ValidateConfig() {
 	if len(c.Resources) == 0 {
 		logger.L().Fatal("resources are missing")
 	}
+	for _, r := range c.Resources {
+		if !r.Strategy.IsValid() {
+			logger.L().Fatal("invalid resource strategy", helpers.String("resource", r.String()), helpers.String("strategy", string(r.Strategy)))
+		}
+		if !r.Direction.IsValid() {
+			logger.L().Fatal("invalid resource direction", helpers.String("resource", r.String()), helpers.String("direction", string(r.Direction)))
+		}
+		if !r.ConflictPolicy.IsValid() {
+			logger.L().Fatal("invalid resource conflict policy", helpers.String("resource", r.String()), helpers.String("conflictPolicy", string(r.ConflictPolicy)))
+		}
+		if !r.ReconciliationMode.IsValid() {
+			logger.L().Fatal("invalid resource reconciliation mode", helpers.String("resource", r.String()), helpers.String("reconciliationMode", string(r.ReconciliationMode)))
+		}
+		if !r.OversizePolicy.IsValid() {
+			logger.L().Fatal("invalid resource oversize policy", helpers.String("resource", r.String()), helpers.String("oversizePolicy", string(r.OversizePolicy)))
+		}
+		if r.CooldownSeconds < 0 || r.MaxDelaySeconds < 0 {
+			logger.L().Fatal("invalid resource cooldown", helpers.String("resource", r.String()), helpers.Int("cooldownSeconds", r.CooldownSeconds), helpers.Int("maxDelaySeconds", r.MaxDelaySeconds))
+		}
+		for _, field := range r.IgnoredFields {
+			if !strings.HasPrefix(field, ".") {
+				logger.L().Fatal("invalid resource ignored field, must start with a dot", helpers.String("resource", r.String()), helpers.String("field", field))
+			}
+		}
+	}
+	if !slices.ContainsFunc(c.Resources, func(r Resource) bool { return r.Direction.Writable() }) {
+		logger.L().Warning("no resource is writable, all writes from the backend will be refused, set direction to backendToCluster or bidirectional to allow them")
+	}
 }
@@ -70,9 +70,9 @@ func TestLoadConfig(t *testing.T) {
 					Account:     "11111111-2222-3333-4444-11111111",
 					AccessKey:   "xxxxxxxx-1111-1111-1111-xxxxxxxx",
 					Resources: []Resource{
-						{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "patch"},
-						{Group: "apps", Version: "v1", Resource: "statefulsets", Strategy: "patch"},
-						{Group: "spdx.softwarecomposition.kubescape.io", Version: "v1beta1", Resource: "applicationprofiles", Strategy: "patch"},
+						{Group: "apps", Version: "v1", Resource: "deployments", Strategy: "patch", Direction: "clusterToBackend"},
+						{Group: "apps", Version: "v1", Resource: "statefulsets", Strategy: "patch", Direction: "clusterToBackend"},
+						{Group: "spdx.softwarecomposition.kubescape.io", Version: "v1beta1", Resource: "applicationprofiles", Strategy: "patch", Direction: "bidirectional", ListThenGet: ptr.To(true)},
 					},
 				},
 			},
@@ -198,3 +198,100 @@ func TestResource_String(t *testing.T) {
 		})
 	}
 }
+
+func TestResource_GetListThenGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource Resource
+		want     bool
+	}{
+		{
+			name:     "storage group by default",
+			resource: Resource{Group: StorageGroup, Version: "v1beta1", Resource: "sbomsyfts"},
+			want:     true,
+		},
+		{
+			name:     "other groups by default",
+			resource: Resource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:     "disabled for the storage group",
+			resource: Resource{Group: StorageGroup, Version: "v1beta1", Resource: "sbomsyfts", ListThenGet: ptr.To(false)},
+		},
+		{
+			name:     "enabled for another group",
+			resource: Resource{Group: "apps", Version: "v1", Resource: "deployments", ListThenGet: ptr.To(true)},
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.resource.GetListThenGet())
+		})
+	}
+}
+
+func TestShadowStore_GetMaxSizeMB(t *testing.T) {
+	tests := []struct {
+		name  string
+		store ShadowStore
+		want  int
+	}{
+		{
+			name:  "default",
+			store: ShadowStore{Path: "/data"},
+			want:  DefaultShadowStoreMaxSizeMB,
+		},
+		{
+			name:  "configured",
+			store: ShadowStore{Path: "/data", MaxSizeMB: 10},
+			want:  10,
+		},
+		{
+			name:  "unbounded",
+			store: ShadowStore{Path: "/data", MaxSizeMB: -1},
+			want:  -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.store.GetMaxSizeMB())
+		})
+	}
+}
+
+func TestLeaderElection_WithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		in   LeaderElection
+		want LeaderElection
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"POD_NAMESPACE": ""},
+			in:   LeaderElection{Enabled: true},
+			want: LeaderElection{Enabled: true, LeaseName: "synchronizer", Namespace: "kubescape", LeaseDurationSeconds: 15, RenewDeadlineSeconds: 10, RetryPeriodSeconds: 2},
+		},
+		{
+			name: "namespace from environment",
+			env:  map[string]string{"POD_NAMESPACE": "ns"},
+			in:   LeaderElection{Enabled: true},
+			want: LeaderElection{Enabled: true, LeaseName: "synchronizer", Namespace: "ns", LeaseDurationSeconds: 15, RenewDeadlineSeconds: 10, RetryPeriodSeconds: 2},
+		},
+		{
+			name: "configured values are kept",
+			env:  map[string]string{"POD_NAMESPACE": "ns"},
+			in:   LeaderElection{Enabled: true, LeaseName: "lease", Namespace: "other", LeaseDurationSeconds: 30, RenewDeadlineSeconds: 20, RetryPeriodSeconds: 5},
+			want: LeaderElection{Enabled: true, LeaseName: "lease", Namespace: "other", LeaseDurationSeconds: 30, RenewDeadlineSeconds: 20, RetryPeriodSeconds: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			assert.Equal(t, tt.want, tt.in.WithDefaults())
+		})
+	}
+}
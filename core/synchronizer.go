@@ -64,12 +64,15 @@ func newSynchronizer(mainCtx context.Context, adapter adapters.Adapter, conn net
 		return nil, fmt.Errorf("unable to create outgoing message pool: %w", err)
 	}
 	callbacks := domain.Callbacks{
-		DeleteObject: s.DeleteObjectCallback,
-		GetObject:    s.GetObjectCallback,
-		PatchObject:  s.PatchObjectCallback,
-		PutObject:    s.PutObjectCallback,
-		VerifyObject: s.VerifyObjectCallback,
-		Batch:        s.BatchCallback,
+		DeleteObject:     s.DeleteObjectCallback,
+		GetObject:        s.GetObjectCallback,
+		PatchObject:      s.PatchObjectCallback,
+		PutObject:        s.PutObjectCallback,
+		VerifyObject:     s.VerifyObjectCallback,
+		Batch:            s.BatchCallback,
+		WriteFailed:      s.WriteFailedCallback,
+		ResourceStatus:   s.ResourceStatusCallback,
+		PermissionReport: s.PermissionReportCallback,
 	}
 	adapter.RegisterCallbacks(mainCtx, callbacks)
 	return s, nil
@@ -133,8 +136,8 @@ func (s *Synchronizer) GetObjectCallback(ctx context.Context, id domain.KindName
 	return nil
 }
 
-func (s *Synchronizer) PatchObjectCallback(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
-	err := s.sendPatchObject(ctx, id, checksum, patch)
+func (s *Synchronizer) PatchObjectCallback(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
+	err := s.sendPatchObject(ctx, id, checksum, patchType, patch)
 	if err != nil {
 		return fmt.Errorf("send patch: %w", err)
 	}
@@ -157,6 +160,30 @@ func (s *Synchronizer) VerifyObjectCallback(ctx context.Context, id domain.KindN
 	return nil
 }
 
+func (s *Synchronizer) WriteFailedCallback(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	err := s.sendWriteFailed(ctx, id, reason, message)
+	if err != nil {
+		return fmt.Errorf("send write failed: %w", err)
+	}
+	return nil
+}
+
+func (s *Synchronizer) ResourceStatusCallback(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	err := s.sendResourceStatus(ctx, kind, state, message)
+	if err != nil {
+		return fmt.Errorf("send resource status: %w", err)
+	}
+	return nil
+}
+
+func (s *Synchronizer) PermissionReportCallback(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	err := s.sendPermissionReport(ctx, kind, missing, message)
+	if err != nil {
+		return fmt.Errorf("send permission report: %w", err)
+	}
+	return nil
+}
+
 func (s *Synchronizer) Start(ctx context.Context) error {
 	hostname, _ := os.Hostname()
 
@@ -302,6 +329,7 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 				Name:            msg.Name,
 				Namespace:       msg.Namespace,
 				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
 			}
 			err := s.handleSyncGetObject(ctx, id, []byte(msg.BaseObject))
 			if err != nil {
@@ -330,6 +358,7 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 				Name:            msg.Name,
 				Namespace:       msg.Namespace,
 				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
 			}
 			err := s.handleSyncNewChecksum(ctx, id, msg.Checksum)
 			if err != nil {
@@ -358,6 +387,7 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 				Name:            msg.Name,
 				Namespace:       msg.Namespace,
 				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
 			}
 			err := s.handleSyncObjectDeleted(ctx, id)
 			if err != nil {
@@ -386,8 +416,9 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 				Name:            msg.Name,
 				Namespace:       msg.Namespace,
 				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
 			}
-			err := s.handleSyncPatchObject(ctx, id, msg.Checksum, []byte(msg.Patch))
+			err := s.handleSyncPatchObject(ctx, id, msg.Checksum, msg.PatchType, []byte(msg.Patch))
 			if err != nil {
 				logger.L().Ctx(ctx).Error("error handling message", helpers.Error(err),
 					helpers.String("account", clientId.Account),
@@ -414,6 +445,7 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 				Name:            msg.Name,
 				Namespace:       msg.Namespace,
 				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
 			}
 			err := s.handleSyncPutObject(ctx, id, []byte(msg.Object))
 			if err != nil {
@@ -425,6 +457,79 @@ func (s *Synchronizer) listenForSyncEvents(ctx context.Context) error {
 					helpers.String("msgid", msg.MsgId))
 				return
 			}
+		case domain.EventWriteFailed:
+			var msg domain.WriteFailed
+			err = json.Unmarshal(data, &msg)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("cannot unmarshal message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", generic.Event.Value()),
+					helpers.String("kind", generic.Kind.String()),
+					helpers.String("msgid", generic.MsgId))
+				return
+			}
+			id := domain.KindName{
+				Kind:            msg.Kind,
+				Name:            msg.Name,
+				Namespace:       msg.Namespace,
+				ResourceVersion: msg.ResourceVersion,
+				UID:             msg.UID,
+			}
+			err := s.handleSyncWriteFailed(ctx, id, domain.WriteFailureReason(msg.Reason), msg.Message)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("error handling message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", msg.Event.Value()),
+					helpers.String("id", id.String()),
+					helpers.String("msgid", msg.MsgId))
+				return
+			}
+		case domain.EventResourceStatus:
+			var msg domain.ResourceStatus
+			err = json.Unmarshal(data, &msg)
+			if err != nil || msg.Kind == nil {
+				logger.L().Ctx(ctx).Error("cannot unmarshal message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", generic.Event.Value()),
+					helpers.String("kind", generic.Kind.String()),
+					helpers.String("msgid", generic.MsgId))
+				return
+			}
+			err := s.handleSyncResourceStatus(ctx, *msg.Kind, domain.ResourceState(msg.State), msg.Message)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("error handling message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", msg.Event.Value()),
+					helpers.String("kind", msg.Kind.String()),
+					helpers.String("msgid", msg.MsgId))
+				return
+			}
+		case domain.EventPermissionReport:
+			var msg domain.PermissionReport
+			err = json.Unmarshal(data, &msg)
+			if err != nil || msg.Kind == nil {
+				logger.L().Ctx(ctx).Error("cannot unmarshal message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", generic.Event.Value()),
+					helpers.String("kind", generic.Kind.String()),
+					helpers.String("msgid", generic.MsgId))
+				return
+			}
+			err := s.handleSyncPermissionReport(ctx, *msg.Kind, msg.Missing, msg.Message)
+			if err != nil {
+				logger.L().Ctx(ctx).Error("error handling message", helpers.Error(err),
+					helpers.String("account", clientId.Account),
+					helpers.String("cluster", clientId.Cluster),
+					helpers.Interface("event", msg.Event.Value()),
+					helpers.String("kind", msg.Kind.String()),
+					helpers.String("msgid", msg.MsgId))
+				return
+			}
 		}
 	})
 	if err != nil {
@@ -489,8 +594,8 @@ func (s *Synchronizer) handleSyncObjectDeleted(ctx context.Context, id domain.Ki
 	return nil
 }
 
-func (s *Synchronizer) handleSyncPatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
-	err := s.adapter.PatchObject(ctx, id, checksum, patch)
+func (s *Synchronizer) handleSyncPatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
+	err := s.adapter.PatchObject(ctx, id, checksum, patchType, patch)
 	if err != nil {
 		return fmt.Errorf("patch object: %w", err)
 	}
@@ -505,18 +610,44 @@ func (s *Synchronizer) handleSyncPutObject(ctx context.Context, id domain.KindNa
 	return nil
 }
 
+func (s *Synchronizer) handleSyncWriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	err := s.adapter.WriteFailed(ctx, id, reason, message)
+	if err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+	return nil
+}
+
+func (s *Synchronizer) handleSyncResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	err := s.adapter.ResourceStatus(ctx, kind, state, message)
+	if err != nil {
+		return fmt.Errorf("resource status: %w", err)
+	}
+	return nil
+}
+
+func (s *Synchronizer) handleSyncPermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	err := s.adapter.PermissionReport(ctx, kind, missing, message)
+	if err != nil {
+		return fmt.Errorf("permission report: %w", err)
+	}
+	return nil
+}
+
 func (s *Synchronizer) sendGetObject(ctx context.Context, id domain.KindName, baseObject []byte) error {
 	event := domain.EventGetObject
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	msg := domain.GetObject{
-		BaseObject: string(baseObject),
-		Depth:      depth + 1,
-		Event:      &event,
-		Kind:       id.Kind,
-		MsgId:      msgId,
-		Name:       id.Name,
-		Namespace:  id.Namespace,
+		BaseObject:      string(baseObject),
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -543,13 +674,15 @@ func (s *Synchronizer) sendNewChecksum(ctx context.Context, id domain.KindName,
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	msg := domain.NewChecksum{
-		Checksum:  checksum,
-		Depth:     depth + 1,
-		Event:     &event,
-		Kind:      id.Kind,
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
+		Checksum:        checksum,
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -579,12 +712,14 @@ func (s *Synchronizer) sendObjectDeleted(ctx context.Context, id domain.KindName
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	msg := domain.ObjectDeleted{
-		Depth:     depth + 1,
-		Event:     &event,
-		Kind:      id.Kind,
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -605,20 +740,23 @@ func (s *Synchronizer) sendObjectDeleted(ctx context.Context, id domain.KindName
 	return nil
 }
 
-func (s *Synchronizer) sendPatchObject(ctx context.Context, id domain.KindName, checksum string, patch []byte) error {
+func (s *Synchronizer) sendPatchObject(ctx context.Context, id domain.KindName, checksum string, patchType domain.PatchType, patch []byte) error {
 	event := domain.EventPatchObject
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 
 	msg := domain.PatchObject{
-		Checksum:  checksum,
-		Depth:     depth + 1,
-		Event:     &event,
-		Kind:      id.Kind,
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
-		Patch:     string(patch),
+		Checksum:        checksum,
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Patch:           string(patch),
+		PatchType:       patchType,
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -639,6 +777,7 @@ func (s *Synchronizer) sendPatchObject(ctx context.Context, id domain.KindName,
 		helpers.String("namespace", msg.Namespace),
 		helpers.String("name", msg.Name),
 		helpers.String("checksum", msg.Checksum),
+		helpers.String("patch type", string(msg.PatchType)),
 		helpers.Int("patch size", len(msg.Patch)))
 	return nil
 }
@@ -697,13 +836,15 @@ func (s *Synchronizer) sendPutObject(ctx context.Context, id domain.KindName, ob
 	depth := ctx.Value(domain.ContextKeyDepth).(int)
 	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
 	msg := domain.PutObject{
-		Depth:     depth + 1,
-		Event:     &event,
-		Kind:      id.Kind,
-		MsgId:     msgId,
-		Name:      id.Name,
-		Namespace: id.Namespace,
-		Object:    string(object),
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Object:          string(object),
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -725,3 +866,102 @@ func (s *Synchronizer) sendPutObject(ctx context.Context, id domain.KindName, ob
 		helpers.Int("object size", len(msg.Object)))
 	return nil
 }
+
+func (s *Synchronizer) sendWriteFailed(ctx context.Context, id domain.KindName, reason domain.WriteFailureReason, message string) error {
+	event := domain.EventWriteFailed
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	msg := domain.WriteFailed{
+		Depth:           depth + 1,
+		Event:           &event,
+		Kind:            id.Kind,
+		Message:         message,
+		MsgId:           msgId,
+		Name:            id.Name,
+		Namespace:       id.Namespace,
+		Reason:          string(reason),
+		ResourceVersion: id.ResourceVersion,
+		UID:             id.UID,
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal write failed message: %w", err)
+	}
+	err = s.outPool.Invoke(data)
+	if err != nil {
+		return fmt.Errorf("invoke outPool on write failed message: %w", err)
+	}
+
+	clientId := utils.ClientIdentifierFromContext(ctx)
+	logger.L().Debug("sent write failed message",
+		helpers.String("account", clientId.Account),
+		helpers.String("cluster", clientId.Cluster),
+		helpers.String("kind", msg.Kind.String()),
+		helpers.String("msgid", msg.MsgId),
+		helpers.String("namespace", msg.Namespace),
+		helpers.String("name", msg.Name),
+		helpers.String("reason", msg.Reason))
+	return nil
+}
+
+func (s *Synchronizer) sendResourceStatus(ctx context.Context, kind domain.Kind, state domain.ResourceState, message string) error {
+	event := domain.EventResourceStatus
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	msg := domain.ResourceStatus{
+		Depth:   depth + 1,
+		Event:   &event,
+		Kind:    &kind,
+		Message: message,
+		MsgId:   msgId,
+		State:   string(state),
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal resource status message: %w", err)
+	}
+	err = s.outPool.Invoke(data)
+	if err != nil {
+		return fmt.Errorf("invoke outPool on resource status message: %w", err)
+	}
+
+	clientId := utils.ClientIdentifierFromContext(ctx)
+	logger.L().Debug("sent resource status message",
+		helpers.String("account", clientId.Account),
+		helpers.String("cluster", clientId.Cluster),
+		helpers.String("kind", kind.String()),
+		helpers.String("msgid", msg.MsgId),
+		helpers.String("state", msg.State))
+	return nil
+}
+
+func (s *Synchronizer) sendPermissionReport(ctx context.Context, kind domain.Kind, missing []string, message string) error {
+	event := domain.EventPermissionReport
+	depth := ctx.Value(domain.ContextKeyDepth).(int)
+	msgId := ctx.Value(domain.ContextKeyMsgId).(string)
+	msg := domain.PermissionReport{
+		Depth:   depth + 1,
+		Event:   &event,
+		Kind:    &kind,
+		Message: message,
+		Missing: missing,
+		MsgId:   msgId,
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal permission report message: %w", err)
+	}
+	err = s.outPool.Invoke(data)
+	if err != nil {
+		return fmt.Errorf("invoke outPool on permission report message: %w", err)
+	}
+
+	clientId := utils.ClientIdentifierFromContext(ctx)
+	logger.L().Debug("sent permission report message",
+		helpers.String("account", clientId.Account),
+		helpers.String("cluster", clientId.Cluster),
+		helpers.String("kind", kind.String()),
+		helpers.String("msgid", msg.MsgId),
+		helpers.Int("missing", len(msg.Missing)))
+	return nil
+}
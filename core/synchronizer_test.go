@@ -96,6 +96,22 @@ func TestSynchronizer_ObjectModified(t *testing.T) {
 	assert.Equal(t, objectClientV2, serverObj)
 }
 
+func TestSynchronizer_ObjectModifiedJSONPatch(t *testing.T) {
+	ctx, clientAdapter, serverAdapter := initTest(t)
+	clientAdapter.PatchType = domain.JSONPatch
+	// pre: add object
+	clientAdapter.Resources[kindDeployment.String()] = object
+	serverAdapter.Resources[kindDeployment.String()] = object
+	// modify object
+	err := clientAdapter.TestCallPutOrPatch(ctx, kindDeployment, object, objectClientV2)
+	assert.NoError(t, err)
+	time.Sleep(1 * time.Second)
+	// check object modified
+	serverObj, ok := serverAdapter.Resources[kindDeployment.String()]
+	assert.True(t, ok)
+	assert.Equal(t, objectClientV2, serverObj)
+}
+
 func TestSynchronizer_ObjectModifiedOnBothSides(t *testing.T) {
 	ctx, clientAdapter, serverAdapter := initTest(t)
 	// pre: add object
@@ -13,6 +13,9 @@ const (
 	EventPutObject
 	EventPing
 	EventBatch
+	EventWriteFailed
+	EventResourceStatus
+	EventPermissionReport
 )
 
 // Value returns the value of the enum.
@@ -23,15 +26,18 @@ func (op Event) Value() any {
 	return EventValues[op]
 }
 
-var EventValues = []any{"newChecksum", "objectAdded", "objectDeleted", "objectModified", "getObject", "patchObject", "putObject", "ping", "batch"}
+var EventValues = []any{"newChecksum", "objectAdded", "objectDeleted", "objectModified", "getObject", "patchObject", "putObject", "ping", "batch", "writeFailed", "resourceStatus", "permissionReport"}
 var ValuesToEvent = map[any]Event{
-	EventValues[EventNewChecksum]:    EventNewChecksum,
-	EventValues[EventObjectAdded]:    EventObjectAdded,
-	EventValues[EventObjectDeleted]:  EventObjectDeleted,
-	EventValues[EventObjectModified]: EventObjectModified,
-	EventValues[EventGetObject]:      EventGetObject,
-	EventValues[EventPatchObject]:    EventPatchObject,
-	EventValues[EventPutObject]:      EventPutObject,
-	EventValues[EventPing]:           EventPing,
-	EventValues[EventBatch]:          EventBatch,
+	EventValues[EventNewChecksum]:      EventNewChecksum,
+	EventValues[EventObjectAdded]:      EventObjectAdded,
+	EventValues[EventObjectDeleted]:    EventObjectDeleted,
+	EventValues[EventObjectModified]:   EventObjectModified,
+	EventValues[EventGetObject]:        EventGetObject,
+	EventValues[EventPatchObject]:      EventPatchObject,
+	EventValues[EventPutObject]:        EventPutObject,
+	EventValues[EventPing]:             EventPing,
+	EventValues[EventBatch]:            EventBatch,
+	EventValues[EventWriteFailed]:      EventWriteFailed,
+	EventValues[EventResourceStatus]:   EventResourceStatus,
+	EventValues[EventPermissionReport]: EventPermissionReport,
 }
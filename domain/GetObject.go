@@ -3,6 +3,7 @@ package domain
 // GetObject represents a GetObject model.
 type GetObject struct {
 	ResourceVersion      int
+	UID                  string
 	BaseObject           string
 	Depth                int
 	Event                *Event
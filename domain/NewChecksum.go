@@ -3,6 +3,7 @@ package domain
 // NewChecksum represents a NewChecksum model.
 type NewChecksum struct {
 	ResourceVersion      int
+	UID                  string
 	Checksum             string
 	Depth                int
 	Event                *Event
@@ -3,6 +3,7 @@ package domain
 // ObjectDeleted represents a ObjectDeleted model.
 type ObjectDeleted struct {
 	ResourceVersion      int
+	UID                  string
 	Depth                int
 	Event                *Event
 	Kind                 *Kind
@@ -4,6 +4,7 @@ package domain
 type PatchObject struct {
 	Checksum             string
 	ResourceVersion      int
+	UID                  string
 	Depth                int
 	Event                *Event
 	Kind                 *Kind
@@ -11,5 +12,6 @@ type PatchObject struct {
 	Name                 string
 	Namespace            string
 	Patch                string
+	PatchType            PatchType
 	AdditionalProperties map[string]interface{}
 }
@@ -0,0 +1,12 @@
+package domain
+
+// PermissionReport represents a PermissionReport model.
+type PermissionReport struct {
+	Depth                int
+	Event                *Event
+	Kind                 *Kind
+	Message              string
+	Missing              []string
+	MsgId                string
+	AdditionalProperties map[string]interface{}
+}
@@ -3,6 +3,7 @@ package domain
 // PutObject represents a PutObject model.
 type PutObject struct {
 	ResourceVersion      int
+	UID                  string
 	Depth                int
 	Event                *Event
 	Kind                 *Kind
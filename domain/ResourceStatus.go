@@ -0,0 +1,12 @@
+package domain
+
+// ResourceStatus represents a ResourceStatus model.
+type ResourceStatus struct {
+	Depth                int
+	Event                *Event
+	Kind                 *Kind
+	Message              string
+	MsgId                string
+	State                string
+	AdditionalProperties map[string]interface{}
+}
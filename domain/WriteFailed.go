@@ -0,0 +1,16 @@
+package domain
+
+// WriteFailed represents a WriteFailed model.
+type WriteFailed struct {
+	ResourceVersion      int
+	UID                  string
+	Depth                int
+	Event                *Event
+	Kind                 *Kind
+	Message              string
+	MsgId                string
+	Name                 string
+	Namespace            string
+	Reason               string
+	AdditionalProperties map[string]interface{}
+}
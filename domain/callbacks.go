@@ -3,10 +3,13 @@ package domain
 import "context"
 
 type Callbacks struct {
-	DeleteObject func(ctx context.Context, id KindName) error
-	GetObject    func(ctx context.Context, id KindName, baseObject []byte) error
-	PatchObject  func(ctx context.Context, id KindName, checksum string, patch []byte) error
-	PutObject    func(ctx context.Context, id KindName, object []byte) error
-	VerifyObject func(ctx context.Context, id KindName, checksum string) error
-	Batch        func(ctx context.Context, kind Kind, batchType BatchType, items BatchItems) error
+	DeleteObject     func(ctx context.Context, id KindName) error
+	GetObject        func(ctx context.Context, id KindName, baseObject []byte) error
+	PatchObject      func(ctx context.Context, id KindName, checksum string, patchType PatchType, patch []byte) error
+	PutObject        func(ctx context.Context, id KindName, object []byte) error
+	VerifyObject     func(ctx context.Context, id KindName, checksum string) error
+	Batch            func(ctx context.Context, kind Kind, batchType BatchType, items BatchItems) error
+	WriteFailed      func(ctx context.Context, id KindName, reason WriteFailureReason, message string) error
+	ResourceStatus   func(ctx context.Context, kind Kind, state ResourceState, message string) error
+	PermissionReport func(ctx context.Context, kind Kind, missing []string, message string) error
 }
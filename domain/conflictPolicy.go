@@ -0,0 +1,20 @@
+package domain
+
+// ConflictPolicy tells how to handle server-side apply conflicts with other field managers.
+type ConflictPolicy string
+
+//goland:noinspection GoUnusedConst
+const (
+	ConflictReport ConflictPolicy = "report" // default, the write fails and is reported to the backend
+	ConflictForce  ConflictPolicy = "force"  // take ownership of the conflicting fields
+	ConflictSkip   ConflictPolicy = "skip"   // leave the object untouched
+)
+
+// IsValid returns true if the policy is known, empty means ConflictReport.
+func (p ConflictPolicy) IsValid() bool {
+	switch p {
+	case "", ConflictReport, ConflictForce, ConflictSkip:
+		return true
+	}
+	return false
+}
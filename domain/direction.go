@@ -0,0 +1,30 @@
+package domain
+
+// Direction tells which side of the synchronization owns a resource.
+type Direction string
+
+//goland:noinspection GoUnusedConst
+const (
+	ClusterToBackend Direction = "clusterToBackend" // default, objects are only sent to the backend
+	BackendToCluster Direction = "backendToCluster" // objects are only written from the backend into the cluster
+	Bidirectional    Direction = "bidirectional"
+)
+
+// IsValid returns true if the direction is known, empty means ClusterToBackend.
+func (d Direction) IsValid() bool {
+	switch d {
+	case "", ClusterToBackend, BackendToCluster, Bidirectional:
+		return true
+	}
+	return false
+}
+
+// Watched returns true if changes in the cluster are sent to the backend.
+func (d Direction) Watched() bool {
+	return d != BackendToCluster
+}
+
+// Writable returns true if the backend is allowed to write objects into the cluster.
+func (d Direction) Writable() bool {
+	return d == BackendToCluster || d == Bidirectional
+}
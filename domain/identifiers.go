@@ -14,6 +14,7 @@ type KindName struct {
 	Name            string
 	Namespace       string
 	ResourceVersion int
+	UID             string // empty when unknown
 }
 
 func (c KindName) String() string {
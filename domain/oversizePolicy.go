@@ -0,0 +1,20 @@
+package domain
+
+// OversizePolicy tells what to do with objects larger than the configured maximum size.
+type OversizePolicy string
+
+//goland:noinspection GoUnusedConst
+const (
+	OversizeSkip     OversizePolicy = "skip"     // default, the object is not sent and an error is logged
+	OversizeMetadata OversizePolicy = "metadata" // only apiVersion, kind and metadata are sent
+	OversizeTruncate OversizePolicy = "truncate" // the largest top-level fields are dropped until the object fits
+)
+
+// IsValid returns true if the policy is known, empty means OversizeSkip.
+func (p OversizePolicy) IsValid() bool {
+	switch p {
+	case "", OversizeSkip, OversizeMetadata, OversizeTruncate:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package domain
+
+// PatchType tells how a patch has to be applied.
+type PatchType string
+
+//goland:noinspection GoUnusedConst
+const (
+	MergePatch PatchType = "merge" // JSON merge patch (RFC 7386), also used when empty
+	JSONPatch  PatchType = "json"  // JSON patch (RFC 6902)
+)
+
+// IsValid returns true if the patch type is known, empty is valid and means MergePatch.
+func (p PatchType) IsValid() bool {
+	switch p {
+	case "", MergePatch, JSONPatch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package domain
+
+// ReconciliationMode tells how a reconciliation batch decides whether an object differs from the backend.
+type ReconciliationMode string
+
+//goland:noinspection GoUnusedConst
+const (
+	ReconcileByResourceVersion ReconciliationMode = "resourceVersion" // objects differ when their resourceVersions differ
+	ReconcileByChecksum        ReconciliationMode = "checksum"        // objects differ when their checksums differ
+	ReconcileHybrid            ReconciliationMode = "hybrid"          // default, equal resourceVersions are a fast path, checksums decide otherwise
+)
+
+// IsValid returns true if the mode is known, empty means ReconcileHybrid.
+func (m ReconciliationMode) IsValid() bool {
+	switch m {
+	case "", ReconcileByResourceVersion, ReconcileByChecksum, ReconcileHybrid:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package domain
+
+// ResourceState tells whether a resource is synchronized.
+type ResourceState string
+
+//goland:noinspection GoUnusedConst
+const (
+	ResourceHealthy  ResourceState = "healthy"
+	ResourceDegraded ResourceState = "degraded" // the resource cannot be watched, e.g. its CRD is uninstalled or RBAC is missing
+)
@@ -4,6 +4,29 @@ type Strategy string
 
 //goland:noinspection GoUnusedConst
 const (
-	CopyStrategy  Strategy = "copy"
-	PatchStrategy Strategy = "patch"
+	CopyStrategy      Strategy = "copy"
+	PatchStrategy     Strategy = "patch"     // send JSON merge patches (RFC 7386)
+	JSONPatchStrategy Strategy = "jsonpatch" // send JSON patches (RFC 6902)
 )
+
+// IsValid returns true if the strategy is known, empty means CopyStrategy.
+func (s Strategy) IsValid() bool {
+	switch s {
+	case "", CopyStrategy, PatchStrategy, JSONPatchStrategy:
+		return true
+	}
+	return false
+}
+
+// Patches returns true if the strategy sends patches instead of full objects.
+func (s Strategy) Patches() bool {
+	return s == PatchStrategy || s == JSONPatchStrategy
+}
+
+// PatchType returns the type of patches sent by the strategy.
+func (s Strategy) PatchType() PatchType {
+	if s == JSONPatchStrategy {
+		return JSONPatch
+	}
+	return MergePatch
+}
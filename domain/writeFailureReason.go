@@ -0,0 +1,11 @@
+package domain
+
+// WriteFailureReason tells why a write from the backend failed.
+type WriteFailureReason string
+
+//goland:noinspection GoUnusedConst
+const (
+	WriteRefused          WriteFailureReason = "refused"          // the resource is not configured, or not writable
+	WriteConflict         WriteFailureReason = "conflict"         // the write conflicts with another field manager
+	WriteRetriesExhausted WriteFailureReason = "retriesExhausted" // the write kept failing with transient errors
+)
@@ -29,7 +29,8 @@ require (
 	github.com/testcontainers/testcontainers-go/modules/k3s v0.27.0
 	go.uber.org/multierr v1.11.0
 	golang.org/x/net v0.19.0
-	istio.io/pkg v0.0.0-20231221211216-7635388a563e
+	golang.org/x/time v0.5.0
+	gomodules.xyz/jsonpatch/v2 v2.4.0
 	k8s.io/api v0.29.0
 	k8s.io/apimachinery v0.29.0
 	k8s.io/client-go v0.29.0
@@ -155,7 +156,6 @@ require (
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/term v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	golang.org/x/time v0.5.0 // indirect
 	golang.org/x/tools v0.15.0 // indirect
 	google.golang.org/appengine v1.6.8 // indirect
 	google.golang.org/genproto v0.0.0-20231120223509-83a465c0220f // indirect
@@ -15,6 +15,9 @@ const (
 	MsgPropEventValuePutObjectMessage             = "PutObject"
 	MsgPropEventValueServerConnectedMessage       = "ServerConnected"
 	MsgPropEventValueReconciliationRequestMessage = "ReconciliationRequest"
+	MsgPropEventValueWriteFailedMessage           = "WriteFailed"
+	MsgPropEventValueResourceStatusMessage        = "ResourceStatus"
+	MsgPropEventValuePermissionReportMessage      = "PermissionReport"
 )
 
 type DeleteObjectMessage struct {
@@ -26,6 +29,7 @@ type DeleteObjectMessage struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type GetObjectMessage struct {
@@ -38,6 +42,7 @@ type GetObjectMessage struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type NewChecksumMessage struct {
@@ -50,6 +55,7 @@ type NewChecksumMessage struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type NewObjectMessage struct {
@@ -62,6 +68,7 @@ type NewObjectMessage struct {
 	Namespace       string `json:"namespace"`
 	Object          []byte `json:"patch"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type PatchObjectMessage struct {
@@ -74,7 +81,9 @@ type PatchObjectMessage struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	Patch           []byte `json:"patch"`
+	PatchType       string `json:"patchType"` // "merge" (RFC 7386, also when empty) or "json" (RFC 6902)
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type PutObjectMessage struct {
@@ -87,6 +96,7 @@ type PutObjectMessage struct {
 	Namespace       string `json:"namespace"`
 	Object          []byte `json:"patch"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 }
 
 type VerifyObjectMessage struct {
@@ -99,6 +109,41 @@ type VerifyObjectMessage struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
+}
+
+type WriteFailedMessage struct {
+	Cluster         string `json:"cluster"`
+	Account         string `json:"account"`
+	Depth           int    `json:"depth"`
+	Kind            string `json:"kind"`
+	Message         string `json:"message"`
+	MsgId           string `json:"msgId"`
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Reason          string `json:"reason"`
+	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
+}
+
+type ResourceStatusMessage struct {
+	Cluster string `json:"cluster"`
+	Account string `json:"account"`
+	Depth   int    `json:"depth"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	MsgId   string `json:"msgId"`
+	State   string `json:"state"`
+}
+
+type PermissionReportMessage struct {
+	Cluster string   `json:"cluster"`
+	Account string   `json:"account"`
+	Depth   int      `json:"depth"`
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Missing []string `json:"missing"`
+	MsgId   string   `json:"msgId"`
 }
 
 type ServerConnectedMessage struct {
@@ -120,6 +165,7 @@ type ReconciliationRequestMessage struct {
 type ReconciliationRequestObject struct {
 	Checksum        string `json:"checksum"`
 	ResourceVersion int    `json:"resourceVersion"`
+	UID             string `json:"uid"`
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 }
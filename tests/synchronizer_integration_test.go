@@ -924,8 +924,9 @@ func TestSynchronizer_TC06(t *testing.T) {
 	require.NoError(t, err)
 	k8sAppProfile.Spec.Containers[0].Name = "nginx2"
 	// alter shadow object in client before updating k8s, the patch won't include the image change
-	appClient.(*incluster.Client).ShadowObjects["spdx.softwarecomposition.kubescape.io/v1beta1/applicationprofiles/default/test"], err = json.Marshal(k8sAppProfile)
+	shadowObject, err := json.Marshal(k8sAppProfile)
 	require.NoError(t, err)
+	appClient.(*incluster.Client).ShadowObjects.Set("spdx.softwarecomposition.kubescape.io/v1beta1/applicationprofiles/default/test", shadowObject)
 	_, err = td.clusters[0].storageclient.ApplicationProfiles(namespace).Update(context.TODO(), k8sAppProfile, metav1.UpdateOptions{})
 	require.NoError(t, err)
 	time.Sleep(10 * time.Second)
@@ -1,44 +1,74 @@
 package utils
 
 import (
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"istio.io/pkg/cache"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
 const (
-	defaultExpiration = 5 * time.Second
-	evictionInterval  = 1 * time.Second
+	DefaultCooldown  = 5 * time.Second
+	DefaultMaxDelay  = 1 * time.Minute
+	evictionInterval = 1 * time.Second
 )
 
+type queuedEvent struct {
+	event     watch.Event
+	firstSeen time.Time // used to enforce the max delay
+	lastSeen  time.Time // used to enforce the cooldown
+}
+
 // CooldownQueue is a queue that lets clients put events into it with a cooldown
 //
 // When a client puts an event into a queue, it waits for a cooldown period before
 // the event is forwarded to the consumer. If and event for the same key is put into the queue
-// again before the cooldown period is over, the event is overridden and the cooldown period is reset.
+// again before the cooldown period is over, both events are coalesced (see coalesce) and the
+// cooldown period is reset. An event is forwarded anyway once it has been waiting for maxDelay,
+// so that objects changing all the time are still sent.
+// It is safe for concurrent use, and can be stopped from any goroutine.
 type CooldownQueue struct {
-	closed     bool
-	seenEvents cache.ExpiringCache
+	cooldown time.Duration
+	maxDelay time.Duration
+	mu       sync.Mutex
+	closed   bool
+	events   map[string]*queuedEvent
+	stop     chan struct{}
+	enqueued atomic.Int64
+	emitted  atomic.Int64
 	// inner channel for producing events
 	innerChan chan watch.Event
 	// public channel for reading events
 	ResultChan <-chan watch.Event
 }
 
-// NewCooldownQueue returns a new Cooldown Queue
+// NewCooldownQueue returns a new Cooldown Queue with the default cooldown and max delay
 func NewCooldownQueue() *CooldownQueue {
-	events := make(chan watch.Event)
-	callback := func(key, value any) {
-		events <- value.(watch.Event)
+	return NewCooldownQueueWithParams(DefaultCooldown, DefaultMaxDelay)
+}
+
+// NewCooldownQueueWithParams returns a new Cooldown Queue, a zero cooldown or max delay uses the default value
+func NewCooldownQueueWithParams(cooldown, maxDelay time.Duration) *CooldownQueue {
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
 	}
-	c := cache.NewTTLWithCallback(defaultExpiration, evictionInterval, callback)
-	return &CooldownQueue{
-		seenEvents: c,
+	events := make(chan watch.Event)
+	q := &CooldownQueue{
+		cooldown:   cooldown,
+		maxDelay:   maxDelay,
+		events:     map[string]*queuedEvent{},
+		stop:       make(chan struct{}),
 		innerChan:  events,
 		ResultChan: events,
 	}
+	go q.run(min(evictionInterval, cooldown))
+	return q
 }
 
 // makeEventKey creates a unique key for an event from a watcher
@@ -52,19 +82,114 @@ func makeEventKey(e watch.Event) string {
 }
 
 func (q *CooldownQueue) Closed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	return q.closed
 }
 
 // Enqueue enqueues an event in the Cooldown Queue
 func (q *CooldownQueue) Enqueue(e watch.Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if q.closed {
 		return
 	}
+	q.enqueued.Add(1)
+	now := time.Now()
 	eventKey := makeEventKey(e)
-	q.seenEvents.Set(eventKey, e)
+	if queued, ok := q.events[eventKey]; ok {
+		eventType, keep := coalesce(queued.event.Type, e.Type)
+		if !keep {
+			delete(q.events, eventKey)
+			return
+		}
+		queued.event = watch.Event{Type: eventType, Object: e.Object}
+		queued.lastSeen = now
+		return
+	}
+	q.events[eventKey] = &queuedEvent{event: e, firstSeen: now, lastSeen: now}
+}
+
+// coalesce merges the type of a queued event with the type of a newer event for the same object,
+// it returns false if both events cancel each other:
+//
+//	Added    + Modified = Added    (the consumer has never seen the object)
+//	Added    + Deleted  = dropped  (the object lived and died within the cooldown)
+//	Modified + Deleted  = Deleted
+//
+// in all other cases the newer event type is kept. Events are keyed by UID, so a recreated object is
+// a different one: its Deleted and Added events are both delivered.
+// Added events include the ones sent for existing objects when the watch starts, if such an object is
+// deleted within the cooldown, the consumer never sees its deletion, the backend keeps it until the next
+// reconciliation.
+func coalesce(previous, next watch.EventType) (watch.EventType, bool) {
+	switch {
+	case previous == watch.Added && next == watch.Modified:
+		return watch.Added, true
+	case previous == watch.Added && next == watch.Deleted:
+		return "", false
+	}
+	return next, true
+}
+
+// CoalescingRatio returns the share of enqueued events that were merged into another one, between 0 and 1.
+func (q *CooldownQueue) CoalescingRatio() float64 {
+	enqueued := q.enqueued.Load()
+	if enqueued == 0 {
+		return 0
+	}
+	return 1 - float64(q.emitted.Load())/float64(enqueued)
 }
 
 func (q *CooldownQueue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
 	q.closed = true
-	close(q.innerChan)
+	close(q.stop)
+}
+
+// run forwards expired events to the result channel until the queue is stopped.
+func (q *CooldownQueue) run(interval time.Duration) {
+	defer close(q.innerChan)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case now := <-ticker.C:
+			for _, e := range q.expired(now) {
+				q.emitted.Add(1)
+				select {
+				case q.innerChan <- e:
+				case <-q.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+// expired removes and returns the events whose cooldown or max delay is over, oldest first.
+func (q *CooldownQueue) expired(now time.Time) []watch.Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var expired []*queuedEvent
+	for key, queued := range q.events {
+		if now.Sub(queued.lastSeen) >= q.cooldown || now.Sub(queued.firstSeen) >= q.maxDelay {
+			expired = append(expired, queued)
+			delete(q.events, key)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].firstSeen.Before(expired[j].firstSeen)
+	})
+	events := make([]watch.Event, 0, len(expired))
+	for _, queued := range expired {
+		events = append(events, queued.event)
+	}
+	return events
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ var (
 	deploymentAdded = watch.Event{Type: watch.Added, Object: &deployment}
 	podAdded        = watch.Event{Type: watch.Added, Object: &pod}
 	podModified     = watch.Event{Type: watch.Modified, Object: &pod}
+	podDeleted      = watch.Event{Type: watch.Deleted, Object: &pod}
 )
 
 func TestCooldownQueue_Enqueue(t *testing.T) {
@@ -28,7 +30,12 @@ func TestCooldownQueue_Enqueue(t *testing.T) {
 		{
 			name:      "add pod",
 			inEvents:  []watch.Event{deploymentAdded, podAdded, podModified, podModified, podModified},
-			outEvents: []watch.Event{deploymentAdded, podModified},
+			outEvents: []watch.Event{deploymentAdded, podAdded},
+		},
+		{
+			name:      "add and delete pod",
+			inEvents:  []watch.Event{deploymentAdded, podAdded, podModified, podDeleted},
+			outEvents: []watch.Event{deploymentAdded},
 		},
 	}
 	for _, tt := range tests {
@@ -57,6 +64,189 @@ func TestCooldownQueue_Enqueue(t *testing.T) {
 	}
 }
 
+func Test_coalesce(t *testing.T) {
+	tests := []struct {
+		previous watch.EventType
+		next     watch.EventType
+		want     watch.EventType
+		wantKeep bool
+	}{
+		{previous: watch.Added, next: watch.Added, want: watch.Added, wantKeep: true},
+		{previous: watch.Added, next: watch.Modified, want: watch.Added, wantKeep: true},
+		{previous: watch.Added, next: watch.Deleted, wantKeep: false},
+		{previous: watch.Modified, next: watch.Added, want: watch.Added, wantKeep: true},
+		{previous: watch.Modified, next: watch.Modified, want: watch.Modified, wantKeep: true},
+		{previous: watch.Modified, next: watch.Deleted, want: watch.Deleted, wantKeep: true},
+		{previous: watch.Deleted, next: watch.Deleted, want: watch.Deleted, wantKeep: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.previous)+"+"+string(tt.next), func(t *testing.T) {
+			got, keep := coalesce(tt.previous, tt.next)
+			assert.Equal(t, tt.wantKeep, keep)
+			if keep {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCooldownQueue_Enqueue_coalescing(t *testing.T) {
+	podV2 := pod.DeepCopy()
+	podV2.SetResourceVersion("2")
+	tests := []struct {
+		name     string
+		inEvents []watch.Event
+		want     *watch.Event
+	}{
+		{
+			name:     "added then modified is delivered as added with the latest object",
+			inEvents: []watch.Event{podAdded, {Type: watch.Modified, Object: podV2}},
+			want:     &watch.Event{Type: watch.Added, Object: podV2},
+		},
+		{
+			name:     "added then deleted is dropped",
+			inEvents: []watch.Event{podAdded, podDeleted},
+		},
+		{
+			name:     "modified then deleted is delivered as deleted",
+			inEvents: []watch.Event{podModified, podDeleted},
+			want:     &podDeleted,
+		},
+		{
+			name:     "added, deleted then added again is delivered as added",
+			inEvents: []watch.Event{podAdded, podDeleted, podAdded},
+			want:     &podAdded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewCooldownQueueWithParams(100*time.Millisecond, 0)
+			defer q.Stop()
+			for _, e := range tt.inEvents {
+				q.Enqueue(e)
+			}
+			select {
+			case e := <-q.ResultChan:
+				if assert.NotNil(t, tt.want) {
+					assert.Equal(t, *tt.want, e)
+				}
+			case <-time.After(500 * time.Millisecond):
+				assert.Nil(t, tt.want)
+			}
+		})
+	}
+}
+
+func TestCooldownQueue_Enqueue_recreated(t *testing.T) {
+	recreated := pod.DeepCopy()
+	recreated.SetUID("0d7c3c38-5b1e-4c8e-9a55-6d1f3a7b2c11")
+	tests := []struct {
+		name     string
+		inEvents []watch.Event
+		want     []watch.Event
+	}{
+		{
+			// the recreated object has a new UID, both events are delivered in order
+			name:     "deleted then recreated",
+			inEvents: []watch.Event{podDeleted, {Type: watch.Added, Object: recreated}},
+			want:     []watch.Event{podDeleted, {Type: watch.Added, Object: recreated}},
+		},
+		{
+			// the synthetic added event of the watch start is dropped with the deletion,
+			// the backend only learns about it at the next reconciliation
+			name:     "existing object deleted right after the watch started",
+			inEvents: []watch.Event{podAdded, podDeleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewCooldownQueueWithParams(100*time.Millisecond, 0)
+			defer q.Stop()
+			for _, e := range tt.inEvents {
+				q.Enqueue(e)
+				time.Sleep(time.Millisecond) // distinct first seen times, to preserve order
+			}
+			var got []watch.Event
+			timeout := time.After(500 * time.Millisecond)
+			for len(got) < len(tt.want) {
+				select {
+				case e := <-q.ResultChan:
+					got = append(got, e)
+				case <-timeout:
+					t.Fatalf("got %d events, want %d", len(got), len(tt.want))
+				}
+			}
+			select {
+			case e := <-q.ResultChan:
+				t.Fatalf("unexpected event %v", e)
+			case <-time.After(300 * time.Millisecond):
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCooldownQueue_concurrentStop(t *testing.T) {
+	q := NewCooldownQueueWithParams(10*time.Millisecond, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				q.Enqueue(podModified)
+				_ = q.Closed()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			q.Stop()
+		}()
+	}
+	wg.Wait()
+	// the result channel is closed once stopped
+	for range q.ResultChan {
+	}
+	assert.True(t, q.Closed())
+}
+
+func TestCooldownQueue_maxDelay(t *testing.T) {
+	q := NewCooldownQueueWithParams(500*time.Millisecond, 1*time.Second)
+	go func() {
+		// pod keeps changing faster than the cooldown
+		for i := 0; i < 20; i++ {
+			q.Enqueue(podModified)
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+	select {
+	case e := <-q.ResultChan:
+		assert.Equal(t, podModified, e)
+	case <-time.After(1900 * time.Millisecond):
+		t.Error("event not flushed after max delay")
+	}
+	q.Stop()
+	assert.Greater(t, q.CoalescingRatio(), 0.5)
+}
+
+func TestCooldownQueue_CoalescingRatio(t *testing.T) {
+	q := NewCooldownQueueWithParams(100*time.Millisecond, 0)
+	assert.Equal(t, 0.0, q.CoalescingRatio())
+	q.Enqueue(podModified)
+	q.Enqueue(podModified)
+	q.Enqueue(podModified)
+	q.Enqueue(podModified)
+	e := <-q.ResultChan
+	assert.Equal(t, podModified, e)
+	assert.Equal(t, 0.75, q.CoalescingRatio())
+	q.Stop()
+	// no more events after stop
+	q.Enqueue(deploymentAdded)
+	_, ok := <-q.ResultChan
+	assert.False(t, ok)
+	assert.True(t, q.Closed())
+}
+
 // key is only based on the UID of the object
 func Test_makeEventKey(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/kubescape/synchronizer/domain"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+)
+
+// CreatePatch calculates a patch of the given type transforming oldObject into newObject.
+func CreatePatch(patchType domain.PatchType, oldObject, newObject []byte) ([]byte, error) {
+	switch patchType {
+	case "", domain.MergePatch:
+		patch, err := jsonpatch.CreateMergePatch(oldObject, newObject)
+		if err != nil {
+			return nil, fmt.Errorf("create merge patch: %w", err)
+		}
+		return patch, nil
+	case domain.JSONPatch:
+		operations, err := jsonpatchv2.CreatePatch(oldObject, newObject)
+		if err != nil {
+			return nil, fmt.Errorf("create json patch: %w", err)
+		}
+		if operations == nil {
+			operations = []jsonpatchv2.Operation{}
+		}
+		return json.Marshal(operations)
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", patchType)
+	}
+}
+
+// ApplyPatch applies a patch of the given type to object.
+func ApplyPatch(patchType domain.PatchType, object, patch []byte) ([]byte, error) {
+	switch patchType {
+	case "", domain.MergePatch:
+		return jsonpatch.MergePatch(object, patch)
+	case domain.JSONPatch:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("decode json patch: %w", err)
+		}
+		return operations.Apply(object)
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", patchType)
+	}
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/kubescape/synchronizer/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAndApplyPatch(t *testing.T) {
+	oldObject := []byte(`{"kind":"ApplicationProfile","spec":{"execs":[{"path":"/bin/sh"},{"path":"/bin/ls"}]}}`)
+	newObject := []byte(`{"kind":"ApplicationProfile","spec":{"execs":[{"path":"/bin/sh"},{"path":"/bin/ls"},{"path":"/bin/cat"}]}}`)
+	tests := []struct {
+		name      string
+		patchType domain.PatchType
+		wantPatch string
+		wantErr   bool
+	}{
+		{
+			name:      "empty defaults to merge patch",
+			wantPatch: `{"spec":{"execs":[{"path":"/bin/sh"},{"path":"/bin/ls"},{"path":"/bin/cat"}]}}`,
+		},
+		{
+			name:      "merge patch resends the whole array",
+			patchType: domain.MergePatch,
+			wantPatch: `{"spec":{"execs":[{"path":"/bin/sh"},{"path":"/bin/ls"},{"path":"/bin/cat"}]}}`,
+		},
+		{
+			name:      "json patch only adds the new entry",
+			patchType: domain.JSONPatch,
+			wantPatch: `[{"op":"add","path":"/spec/execs/2","value":{"path":"/bin/cat"}}]`,
+		},
+		{
+			name:      "unknown patch type",
+			patchType: "strategic",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := CreatePatch(tt.patchType, oldObject, newObject)
+			if tt.wantErr {
+				assert.Error(t, err)
+				_, err = ApplyPatch(tt.patchType, oldObject, []byte(tt.wantPatch))
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.wantPatch, string(patch))
+			modified, err := ApplyPatch(tt.patchType, oldObject, patch)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(newObject), string(modified))
+		})
+	}
+}
+
+func TestCreatePatch_noChanges(t *testing.T) {
+	object := []byte(`{"kind":"Deployment"}`)
+	patch, err := CreatePatch(domain.JSONPatch, object, object)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(patch))
+	modified, err := ApplyPatch(domain.JSONPatch, object, patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(object), string(modified))
+}
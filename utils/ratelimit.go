@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+var (
+	throttledRequestsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "synchronizer_kubernetes_client_throttled_count",
+		Help: "The total number of requests to the API server delayed by the client-side rate limiter",
+	})
+	throttledSecondsHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "synchronizer_kubernetes_client_throttled_seconds",
+		Help:    "The time requests to the API server were delayed by the client-side rate limiter",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+	rejectedRequestsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "synchronizer_kubernetes_client_rejected_count",
+		Help: "The total number of requests rejected by the API server with 429 Too Many Requests, e.g. by API Priority and Fairness",
+	})
+)
+
+// throttlingRateLimiter records the requests delayed by the rate limiter.
+type throttlingRateLimiter struct {
+	flowcontrol.RateLimiter
+}
+
+func (r throttlingRateLimiter) Wait(ctx context.Context) error {
+	if r.TryAccept() {
+		return nil
+	}
+	start := time.Now()
+	err := r.RateLimiter.Wait(ctx)
+	throttledRequestsCounter.Inc()
+	throttledSecondsHistogram.Observe(time.Since(start).Seconds())
+	return err
+}
+
+// newThrottlingRateLimiter returns a token bucket rate limiter recording throttled requests.
+func newThrottlingRateLimiter(qps float32, burst int) flowcontrol.RateLimiter {
+	return throttlingRateLimiter{RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst)}
+}
+
+// rejectionCountingTransport counts the requests rejected by the API server because of overload.
+type rejectionCountingTransport struct {
+	http.RoundTripper
+}
+
+func (t rejectionCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		rejectedRequestsCounter.Inc()
+	}
+	return resp, err
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// stubRateLimiter accepts requests right away or makes them wait, without depending on the clock
+type stubRateLimiter struct {
+	flowcontrol.RateLimiter
+	accept bool
+	waits  int
+}
+
+func (r *stubRateLimiter) TryAccept() bool {
+	return r.accept
+}
+
+func (r *stubRateLimiter) Wait(context.Context) error {
+	r.waits++
+	return nil
+}
+
+func TestThrottlingRateLimiter_Wait(t *testing.T) {
+	tests := []struct {
+		name          string
+		accept        bool
+		wantThrottled float64
+	}{
+		{
+			name:   "within the burst",
+			accept: true,
+		},
+		{
+			name:          "throttled",
+			wantThrottled: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubRateLimiter{accept: tt.accept}
+			before := testutil.ToFloat64(throttledRequestsCounter)
+			require.NoError(t, throttlingRateLimiter{RateLimiter: stub}.Wait(context.TODO()))
+			assert.Equal(t, before+tt.wantThrottled, testutil.ToFloat64(throttledRequestsCounter))
+			assert.Equal(t, int(tt.wantThrottled), stub.waits)
+		})
+	}
+}
+
+func TestRejectionCountingTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   float64
+	}{
+		{
+			name:   "accepted",
+			status: http.StatusOK,
+		},
+		{
+			name:   "rejected by priority and fairness",
+			status: http.StatusTooManyRequests,
+			want:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			before := testutil.ToFloat64(rejectedRequestsCounter)
+			client := &http.Client{Transport: rejectionCountingTransport{RoundTripper: http.DefaultTransport}}
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, before+tt.want, testutil.ToFloat64(rejectedRequestsCounter))
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/SergJa/jsonhash"
@@ -21,20 +22,30 @@ import (
 	"github.com/google/uuid"
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/synchronizer/config"
 	"github.com/kubescape/synchronizer/domain"
 	"github.com/pmezard/go-difflib/difflib"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 )
 
 func CanonicalHash(in []byte) (string, error) {
-	hash, err := jsonhash.CalculateJsonHash(in, []string{
+	return CanonicalHashExcluding(in)
+}
+
+// CanonicalHashExcluding is CanonicalHash also ignoring the given fields, e.g. ".status.lastHeartbeatTime".
+func CanonicalHashExcluding(in []byte, fields ...string) (string, error) {
+	hash, err := jsonhash.CalculateJsonHash(in, append([]string{
 		".status.conditions", // avoid Pod.status.conditions.lastProbeTime: null
-	})
+	}, fields...))
 	if err != nil {
 		return "", err
 	}
@@ -154,11 +165,34 @@ func typeAndKind(v interface{}) (reflect.Type, reflect.Kind) {
 	return t, k
 }
 
-func NewClient() (dynamic.Interface, error) {
+// NewClientset returns a typed client, used for the resources managed by the synchronizer itself like Leases.
+func NewClientset() (kubernetes.Interface, error) {
 	clusterConfig, err := getConfig()
 	if err != nil {
 		return nil, err
 	}
+	return kubernetes.NewForConfig(clusterConfig)
+}
+
+// NewRESTMapper returns a mapper of the kinds served by the cluster, discovery is cached and refreshed for unknown kinds.
+func NewRESTMapper(clientset kubernetes.Interface) meta.RESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientset.Discovery()))
+}
+
+// NewClient returns the dynamic client used to sync the resources, all its requests share the same rate limiter.
+func NewClient(cfg config.KubernetesClient) (dynamic.Interface, error) {
+	clusterConfig, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	clusterConfig = rest.CopyConfig(clusterConfig)
+	clusterConfig.QPS = cfg.GetQPS()
+	clusterConfig.Burst = cfg.GetBurst()
+	clusterConfig.RateLimiter = newThrottlingRateLimiter(clusterConfig.QPS, clusterConfig.Burst)
+	clusterConfig.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	clusterConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return rejectionCountingTransport{RoundTripper: rt}
+	})
 	dynClient, err := dynamic.NewForConfig(clusterConfig)
 	if err != nil {
 		return nil, err
@@ -166,7 +200,21 @@ func NewClient() (dynamic.Interface, error) {
 	return dynClient, nil
 }
 
+var (
+	clusterConfigOnce sync.Once
+	clusterConfig     *rest.Config
+	clusterConfigErr  error
+)
+
+// getConfig loads the cluster config once, the kubeconfig flag cannot be defined twice.
 func getConfig() (*rest.Config, error) {
+	clusterConfigOnce.Do(func() {
+		clusterConfig, clusterConfigErr = loadConfig()
+	})
+	return clusterConfig, clusterConfigErr
+}
+
+func loadConfig() (*rest.Config, error) {
 	// try in-cluster config first
 	clusterConfig, err := rest.InClusterConfig()
 	if err == nil {
@@ -217,9 +265,19 @@ func ServePprof() {
 	}
 }
 
-func StartLivenessProbe() {
+// StartLivenessProbe serves the liveness probe, with the JSON encoded details returned by status if not nil.
+// Details never fail the probe, as restarting does not help e.g. with a missing CRD.
+func StartLivenessProbe(status func() any) {
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if status == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(status()); err != nil {
+			logger.L().Error("failed to encode health status", helpers.Error(err))
+		}
 	})
 	go func() {
 		if err := http.ListenAndServe(":7888", nil); err != nil {
@@ -63,6 +63,17 @@ func TestCanonicalHash(t *testing.T) {
 	}
 }
 
+func TestCanonicalHashExcluding(t *testing.T) {
+	heartbeat, err := CanonicalHashExcluding([]byte(`{"spec":{"a":"b"},"status":{"lastHeartbeatTime":"1"}}`), ".status.lastHeartbeatTime")
+	assert.NoError(t, err)
+	later, err := CanonicalHashExcluding([]byte(`{"spec":{"a":"b"},"status":{"lastHeartbeatTime":"2"}}`), ".status.lastHeartbeatTime")
+	assert.NoError(t, err)
+	assert.Equal(t, heartbeat, later)
+	changed, err := CanonicalHashExcluding([]byte(`{"spec":{"a":"c"},"status":{"lastHeartbeatTime":"2"}}`), ".status.lastHeartbeatTime")
+	assert.NoError(t, err)
+	assert.NotEqual(t, heartbeat, changed)
+}
+
 func TestContextFromGeneric(t *testing.T) {
 	got := ContextFromGeneric(context.TODO(), domain.Generic{})
 	assert.Equal(t, 0, got.Value(domain.ContextKeyDepth))